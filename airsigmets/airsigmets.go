@@ -0,0 +1,116 @@
+package airsigmets
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/theperiscope/avwx/geo"
+)
+
+type Response struct {
+	XMLName      xml.Name   `xml:"response" json:"-"`
+	Version      string     `xml:"version,attr"`
+	RequestIndex int32      `xml:"request_index"`
+	Errors       []string   `xml:"errors>error"`
+	Warnings     []string   `xml:"warnings>warning"`
+	TimeTakenMs  int32      `xml:"time_taken_ms"`
+	DataSource   DataSource `xml:"data_source"`
+	Request      Request    `xml:"request"`
+	Data         Data       `xml:"data"`
+}
+
+type Request struct {
+	XMLName xml.Name `xml:"request" json:"-"`
+	Type    string   `xml:"type,attr"`
+}
+
+type DataSource struct {
+	XMLName xml.Name `xml:"data_source" json:"-"`
+	Name    string   `xml:"name,attr"`
+}
+
+type Data struct {
+	XMLName    xml.Name    `xml:"data" json:"-"`
+	NumResults int32       `xml:"num_results,attr"`
+	AirSigmets []AirSigmet `xml:"AIRSIGMET"`
+}
+
+type Altitude struct {
+	XMLName  xml.Name `xml:"altitude" json:"-"`
+	MinFtMSL int32    `xml:"min_ft_msl,attr"`
+	MaxFtMSL int32    `xml:"max_ft_msl,attr"`
+}
+
+type Hazard struct {
+	XMLName  xml.Name `xml:"hazard" json:"-"`
+	Type     string   `xml:"type,attr"`
+	Severity string   `xml:"severity,attr"`
+}
+
+// Point and Area are the geo package types shared with the other area products
+type Point = geo.AreaPoint
+
+type Area = geo.Area
+
+type AirSigmet struct {
+	XMLName            xml.Name  `xml:"AIRSIGMET" json:"-"`
+	RawText            string    `xml:"raw_text"`
+	ValidTimeFrom      time.Time `xml:"valid_time_from"`
+	ValidTimeTo        time.Time `xml:"valid_time_to"`
+	Altitude           Altitude  `xml:"altitude"`
+	MovementDirDegrees int32     `xml:"movement_dir_degrees"`
+	MovementSpeedKt    int32     `xml:"movement_speed_kt"`
+	Hazard             Hazard    `xml:"hazard"`
+	AirSigmetType      string    `xml:"airsigmet_type"`
+	Area               Area      `xml:"area"`
+}
+
+// Contains reports whether the point lies inside the AIRMET/SIGMET area
+func (a *AirSigmet) Contains(lat, lon float64) bool {
+	return a.Area.Contains(lat, lon)
+}
+
+// Intersects reports whether the AIRMET/SIGMET area overlaps the bounding box
+func (a *AirSigmet) Intersects(minLat, minLon, maxLat, maxLon float64) bool {
+	return a.Area.Intersects(minLat, minLon, maxLat, maxLon)
+}
+
+// Filter removes all AIRMETs/SIGMETs for which keep returns false
+func (r *Response) Filter(keep func(*AirSigmet) bool) {
+	kept := r.Data.AirSigmets[:0]
+	for i := range r.Data.AirSigmets {
+		if keep(&r.Data.AirSigmets[i]) {
+			kept = append(kept, r.Data.AirSigmets[i])
+		}
+	}
+	r.Data.AirSigmets = kept
+	r.Data.NumResults = int32(len(kept))
+}
+
+func (r *Response) ToRawTextOnly() (s []string) {
+	for _, airSigmet := range r.Data.AirSigmets {
+		s = append(s, airSigmet.RawText)
+	}
+	return
+}
+
+func (r *Response) ToJson() (s string, err error) {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
+
+func (r *Response) ToJsonIndented() (s string, err error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
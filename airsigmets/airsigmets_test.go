@@ -0,0 +1,82 @@
+package airsigmets
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readResponse(t *testing.T) Response {
+	t.Helper()
+
+	b, err := ioutil.ReadFile("testdata/adds_airsigmets.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Response
+	if err := xml.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestDecodeXML(t *testing.T) {
+	r := readResponse(t)
+	if r.Version != "1.2" || r.RequestIndex != 97245513 || r.DataSource.Name != "airsigmets" || r.TimeTakenMs != 5 {
+		t.Errorf("response version/request index/data source/time = %s/%d/%s/%d",
+			r.Version, r.RequestIndex, r.DataSource.Name, r.TimeTakenMs)
+	}
+	if r.Data.NumResults != 2 || len(r.Data.AirSigmets) != 2 {
+		t.Fatalf("got %d AIRMETs/SIGMETs (num_results %d), want 2", len(r.Data.AirSigmets), r.Data.NumResults)
+	}
+
+	sigmet := r.Data.AirSigmets[0]
+	if !strings.HasPrefix(sigmet.RawText, "WSUS32 KKCI 181155\nSIGC\nCONVECTIVE SIGMET 45C") {
+		t.Errorf("raw_text = %q, want the multi-line bulletin", sigmet.RawText)
+	}
+	if !sigmet.ValidTimeFrom.Equal(time.Date(2026, 10, 18, 11, 55, 0, 0, time.UTC)) ||
+		!sigmet.ValidTimeTo.Equal(time.Date(2026, 10, 18, 13, 55, 0, 0, time.UTC)) {
+		t.Errorf("valid time = %v..%v", sigmet.ValidTimeFrom, sigmet.ValidTimeTo)
+	}
+	if sigmet.AirSigmetType != "SIGMET" || sigmet.Hazard.Type != "CONVECTIVE" || sigmet.Hazard.Severity != "SEV" ||
+		sigmet.MovementDirDegrees != 240 || sigmet.MovementSpeedKt != 25 {
+		t.Errorf("type/hazard/movement = %s/%+v/%d@%d", sigmet.AirSigmetType, sigmet.Hazard,
+			sigmet.MovementDirDegrees, sigmet.MovementSpeedKt)
+	}
+	if sigmet.Altitude.MinFtMSL != 0 || sigmet.Altitude.MaxFtMSL != 45000 {
+		t.Errorf("altitude = %+v, want up to 45000", sigmet.Altitude)
+	}
+	if sigmet.Area.NumPoints != 5 || len(sigmet.Area.Points) != 5 {
+		t.Fatalf("area has %d points (num_points %d), want 5", len(sigmet.Area.Points), sigmet.Area.NumPoints)
+	}
+	if p := sigmet.Area.Points[1]; p.Latitude != 37.4 || p.Longitude != -96.9 {
+		t.Errorf("second point = %+v, want 37.4,-96.9", p)
+	}
+
+	airmet := r.Data.AirSigmets[1]
+	if airmet.AirSigmetType != "AIRMET" || airmet.Hazard.Type != "TURB" || airmet.Altitude.MinFtMSL != 18000 ||
+		airmet.Altitude.MaxFtMSL != 40000 || airmet.MovementSpeedKt != 0 {
+		t.Errorf("AIRMET = %+v", airmet)
+	}
+}
+
+func TestAreaFilters(t *testing.T) {
+	r := readResponse(t)
+	sigmet, airmet := &r.Data.AirSigmets[0], &r.Data.AirSigmets[1]
+
+	// Dodge City is inside the convective SIGMET, Denver is outside both
+	if !sigmet.Contains(37.76, -99.97) || sigmet.Contains(39.86, -104.67) || airmet.Contains(37.76, -99.97) {
+		t.Error("Contains does not follow the decoded areas")
+	}
+	if !airmet.Intersects(42, -113, 43, -112) || airmet.Intersects(30, -90, 31, -89) {
+		t.Error("Intersects does not follow the decoded areas")
+	}
+
+	r.Filter(func(a *AirSigmet) bool { return a.Hazard.Type == "TURB" })
+	if len(r.Data.AirSigmets) != 1 || r.Data.NumResults != 1 || r.Data.AirSigmets[0].AirSigmetType != "AIRMET" {
+		t.Errorf("after Filter got %d (num_results %d), want the AIRMET only", len(r.Data.AirSigmets), r.Data.NumResults)
+	}
+}
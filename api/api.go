@@ -1,225 +1,369 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strconv"
-	"strings"
 	"time"
 
+	"github.com/theperiscope/avwx/airsigmets"
+	"github.com/theperiscope/avwx/gairmets"
 	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/pireps"
+	"github.com/theperiscope/avwx/stations"
 	"github.com/theperiscope/avwx/tafs"
 )
 
 const DefaultApiEndPoint = "https://aviationweather.gov/adds/dataserver_current/httpparam"
 
+// Client retrieves data from the Text Data Server. When ADDS error checking is enabled with WithADDSErrors, methods
+// return the decoded response together with an *ADDSError or *ADDSWarnings error.
 type Client interface {
+	GetMetarContext(ctx context.Context, options MetarOptions) (*metars.Response, error)
+	GetTafContext(ctx context.Context, options TafOptions) (*tafs.Response, error)
+	GetAircraftReportsContext(ctx context.Context, options AircraftReportOptions) (*pireps.Response, error)
+	GetAirSigmetsContext(ctx context.Context, options AirSigmetOptions) (*airsigmets.Response, error)
+	GetGAirmetsContext(ctx context.Context, options GAirmetOptions) (*gairmets.Response, error)
+	GetStationsContext(ctx context.Context, options StationInfoOptions) (*stations.Response, error)
+
+	// the methods below are shorthands for the context variants called with context.Background()
 	GetMetar(options MetarOptions) (*metars.Response, error)
 	GetTaf(options TafOptions) (*tafs.Response, error)
+	GetAircraftReports(options AircraftReportOptions) (*pireps.Response, error)
+	GetAirSigmets(options AirSigmetOptions) (*airsigmets.Response, error)
+	GetGAirmets(options GAirmetOptions) (*gairmets.Response, error)
+	GetStations(options StationInfoOptions) (*stations.Response, error)
+
+	// StreamMetars and StreamTafs decode large responses one report at a time
+	StreamMetars(ctx context.Context, options MetarOptions, fn func(metars.Metar) error) error
+	StreamTafs(ctx context.Context, options TafOptions, fn func(tafs.Taf) error) error
 }
 
 type client struct {
-	c           *http.Client
-	ApiEndPoint string
-}
-
-type MetarOptions struct {
-	Stations                 []string
-	StartTime                timeValue
-	EndTime                  timeValue
-	HoursBeforeNow           int32
-	MostRecent               bool
-	MostRecentForEachStation bool
-	MinLat                   float64
-	MaxLat                   float64
-	MinLon                   float64
-	MaxLon                   float64
-	RadialDistance           string
-	FlightPath               []string
-	MinDegreeDistance        float64
-	Fields                   []string
-}
-
-type TafOptions struct {
-	Stations                 []string
-	StartTime                timeValue
-	EndTime                  timeValue
-	TimeType                 string // only difference from MetarOptions
-	HoursBeforeNow           int32
-	MostRecent               bool
-	MostRecentForEachStation bool
-	MinLat                   float64
-	MaxLat                   float64
-	MinLon                   float64
-	MaxLon                   float64
-	RadialDistance           string
-	FlightPath               []string
-	MinDegreeDistance        float64
-	Fields                   []string
-}
-
-func NewClient(apiEndPoint string) Client {
-	c := &http.Client{}
-
-	return &client{
-		c:           c,
+	c              *http.Client
+	ApiEndPoint    string
+	userAgent      string
+	addsErrors     bool
+	fatalWarnings  bool
+	warningHandler func(*ADDSWarnings)
+	retryPolicy    RetryPolicy
+	limiter        *RateLimiter
+	cache          Cache
+	cacheTTL       time.Duration
+	dataSourceTTL  map[string]time.Duration
+	backend        Backend
+	format         Format
+	batchSize      int
+	batchWorkers   int
+}
+
+func NewClient(apiEndPoint string, opts ...ClientOption) Client {
+	c := &client{
+		c:           &http.Client{},
 		ApiEndPoint: apiEndPoint,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 func (c *client) GetMetar(options MetarOptions) (*metars.Response, error) {
-	u, err := url.Parse(c.ApiEndPoint)
+	return c.GetMetarContext(context.Background(), options)
+}
+
+func (c *client) GetMetarContext(ctx context.Context, options MetarOptions) (*metars.Response, error) {
+	if c.batching(options.Stations) {
+		return c.getMetarsBatched(ctx, options)
+	}
+
+	r, err := c.getMetars(ctx, options)
 	if err != nil {
 		return nil, err
 	}
 
-	q := u.Query()
-	q.Set("dataSource", "metars")
-	q.Set("requestType", "retrieve")
-	q.Set("format", "xml")
+	return r, c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+}
 
-	if len(options.Stations) > 0 {
-		q.Set("stationString", strings.Join(options.Stations, " "))
+// getMetars sends a single request, leaving the response's errors and warnings to the caller
+func (c *client) getMetars(ctx context.Context, options MetarOptions) (*metars.Response, error) {
+	if c.backend == BackendDataAPI {
+		return c.getDataApiMetars(ctx, options)
 	}
-	if !time.Time(options.StartTime).IsZero() {
-		q.Set("startTime", options.StartTime.String())
-	}
-	if !time.Time(options.EndTime).IsZero() {
-		q.Set("endTime", options.EndTime.String())
-	}
-	if options.HoursBeforeNow > 0 {
-		q.Set("hoursBeforeNow", strconv.FormatInt(int64(options.HoursBeforeNow), 10))
-	}
-	if options.MostRecent {
-		q.Set("mostRecent", strconv.FormatBool(options.MostRecent))
-	}
-	if options.MostRecentForEachStation {
-		q.Set("mostRecentForEachStation", strconv.FormatBool(options.MostRecentForEachStation))
-	}
-	if options.MinLat != 0 {
-		q.Set("minLat", strconv.FormatFloat(options.MinLat, 'f', -1, 64))
-	}
-	if options.MaxLat != 0 {
-		q.Set("maxLat", strconv.FormatFloat(options.MaxLat, 'f', -1, 64))
-	}
-	if options.MinLon != 0 {
-		q.Set("minLon", strconv.FormatFloat(options.MinLon, 'f', -1, 64))
+
+	var r metars.Response
+	if c.format == FormatCSV {
+		data, err := c.retrieveFormat(ctx, options, FormatCSV)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeMetarsCSV(data, &r); err != nil {
+			return nil, err
+		}
+	} else if err := c.retrieve(ctx, options, &r); err != nil {
+		return nil, err
 	}
-	if options.MaxLon != 0 {
-		q.Set("maxLon", strconv.FormatFloat(options.MaxLon, 'f', -1, 64))
+
+	return &r, nil
+}
+
+func (c *client) GetTaf(options TafOptions) (*tafs.Response, error) {
+	return c.GetTafContext(context.Background(), options)
+}
+
+func (c *client) GetTafContext(ctx context.Context, options TafOptions) (*tafs.Response, error) {
+	if c.batching(options.Stations) {
+		return c.getTafsBatched(ctx, options)
 	}
-	if len(options.RadialDistance) > 0 {
-		q.Set("radialDistance", options.RadialDistance)
+
+	r, err := c.getTafs(ctx, options)
+	if err != nil {
+		return nil, err
 	}
-	if len(options.FlightPath) > 0 {
-		q.Set("flightPath", strings.Join(options.FlightPath, " "))
+
+	return r, c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+}
+
+// getTafs sends a single request, leaving the response's errors and warnings to the caller
+func (c *client) getTafs(ctx context.Context, options TafOptions) (*tafs.Response, error) {
+	if c.backend == BackendDataAPI {
+		return c.getDataApiTafs(ctx, options)
 	}
-	if options.MinDegreeDistance != 0 {
-		q.Set("minDegreeDistance", strconv.FormatFloat(options.MinDegreeDistance, 'f', -1, 64))
+
+	var r tafs.Response
+	if c.format == FormatCSV {
+		data, err := c.retrieveFormat(ctx, options, FormatCSV)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeTafsCSV(data, &r); err != nil {
+			return nil, err
+		}
+	} else if err := c.retrieve(ctx, options, &r); err != nil {
+		return nil, err
 	}
-	if len(options.Fields) > 0 {
-		q.Set("fields", strings.Join(options.Fields, " "))
+
+	return &r, nil
+}
+
+func (c *client) GetAircraftReports(options AircraftReportOptions) (*pireps.Response, error) {
+	return c.GetAircraftReportsContext(context.Background(), options)
+}
+
+func (c *client) GetAircraftReportsContext(ctx context.Context, options AircraftReportOptions) (*pireps.Response, error) {
+	var r pireps.Response
+	if err := c.retrieve(ctx, options, &r); err != nil {
+		return nil, err
 	}
 
-	u.RawQuery = q.Encode()
+	return &r, c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+}
 
-	httpResponse, err := http.Get(u.String())
-	if err != nil {
+func (c *client) GetAirSigmets(options AirSigmetOptions) (*airsigmets.Response, error) {
+	return c.GetAirSigmetsContext(context.Background(), options)
+}
+
+func (c *client) GetAirSigmetsContext(ctx context.Context, options AirSigmetOptions) (*airsigmets.Response, error) {
+	var r airsigmets.Response
+	if err := c.retrieve(ctx, options, &r); err != nil {
 		return nil, err
 	}
 
-	data, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
+	return &r, c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+}
+
+func (c *client) GetGAirmets(options GAirmetOptions) (*gairmets.Response, error) {
+	return c.GetGAirmetsContext(context.Background(), options)
+}
+
+func (c *client) GetGAirmetsContext(ctx context.Context, options GAirmetOptions) (*gairmets.Response, error) {
+	var r gairmets.Response
+	if err := c.retrieve(ctx, options, &r); err != nil {
 		return nil, err
 	}
 
-	var r metars.Response
-	err = xml.Unmarshal(data, &r)
-	if err != nil {
+	return &r, c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+}
+
+func (c *client) GetStations(options StationInfoOptions) (*stations.Response, error) {
+	return c.GetStationsContext(context.Background(), options)
+}
+
+func (c *client) GetStationsContext(ctx context.Context, options StationInfoOptions) (*stations.Response, error) {
+	var r stations.Response
+	if err := c.retrieve(ctx, options, &r); err != nil {
 		return nil, err
+	}
 
+	return &r, c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+}
+
+// retrieve requests the data selected by q from the data server and decodes the XML response into v
+func (c *client) retrieve(ctx context.Context, q query, v interface{}) error {
+	data, err := c.retrieveFormat(ctx, q, FormatXML)
+	if err != nil {
+		return err
 	}
 
-	return &r, nil
+	return xml.Unmarshal(data, v)
 }
 
-func (c *client) GetTaf(options TafOptions) (*tafs.Response, error) {
-	u, err := url.Parse(c.ApiEndPoint)
+// retrieveFormat requests the data selected by q from the data server in the given format and returns the response
+func (c *client) retrieveFormat(ctx context.Context, q query, format Format) ([]byte, error) {
+	u, err := c.retrieveURL(q, format)
 	if err != nil {
 		return nil, err
 	}
 
-	q := u.Query()
-	q.Set("dataSource", "tafs")
-	q.Set("requestType", "retrieve")
-	q.Set("format", "xml")
+	return c.get(ctx, q.DataSource(), u)
+}
 
-	if len(options.Stations) > 0 {
-		q.Set("stationString", strings.Join(options.Stations, " "))
-	}
-	if !time.Time(options.StartTime).IsZero() {
-		q.Set("startTime", options.StartTime.String())
-	}
-	if !time.Time(options.EndTime).IsZero() {
-		q.Set("endTime", options.EndTime.String())
-	}
-	if len(options.TimeType) > 0 {
-		q.Set("timeType", options.TimeType)
+// retrieveURL returns the data server URL requesting the data selected by q in the given format
+func (c *client) retrieveURL(q query, format Format) (string, error) {
+	if c.backend != BackendADDS {
+		return "", errDataApiUnsupported(q.DataSource())
 	}
-	if options.HoursBeforeNow > 0 {
-		q.Set("hoursBeforeNow", strconv.FormatInt(int64(options.HoursBeforeNow), 10))
-	}
-	if options.MostRecent {
-		q.Set("mostRecent", strconv.FormatBool(options.MostRecent))
-	}
-	if options.MostRecentForEachStation {
-		q.Set("mostRecentForEachStation", strconv.FormatBool(options.MostRecentForEachStation))
-	}
-	if options.MinLat != 0 {
-		q.Set("minLat", strconv.FormatFloat(options.MinLat, 'f', -1, 64))
-	}
-	if options.MaxLat != 0 {
-		q.Set("maxLat", strconv.FormatFloat(options.MaxLat, 'f', -1, 64))
-	}
-	if options.MinLon != 0 {
-		q.Set("minLon", strconv.FormatFloat(options.MinLon, 'f', -1, 64))
+
+	u, err := url.Parse(c.ApiEndPoint)
+	if err != nil {
+		return "", err
 	}
-	if options.MaxLon != 0 {
-		q.Set("maxLon", strconv.FormatFloat(options.MaxLon, 'f', -1, 64))
+
+	values := u.Query()
+	values.Set("dataSource", q.DataSource())
+	values.Set("requestType", "retrieve")
+	values.Set("format", string(format))
+	for key, value := range q.Values() {
+		values[key] = value
+	}
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
+
+// get returns the response body for a GET request of u, which queries dataSource. Bodies are served from and saved
+// to the client's cache if it has one. Requests are bound to ctx and retried according to the client's retry policy.
+// Non-2xx responses are reported as *HTTPError and requests that needed more than one attempt fail with *RetryError.
+// When the request fails because ctx was canceled or its deadline passed, the returned error wraps ctx.Err() so
+// callers can test it with errors.Is.
+func (c *client) get(ctx context.Context, dataSource string, u string) ([]byte, error) {
+	ttl, useCache := c.cacheTTLFor(dataSource)
+	if useCache {
+		if data, ok := c.cache.Get(u); ok {
+			return data, nil
+		}
 	}
-	if len(options.RadialDistance) > 0 {
-		q.Set("radialDistance", options.RadialDistance)
+
+	var data []byte
+	err := c.withRetries(ctx, func() error {
+		body, err := c.open(ctx, u)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		data, err = ioutil.ReadAll(body)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("reading response aborted: %w", ctxErr)
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if len(options.FlightPath) > 0 {
-		q.Set("flightPath", strings.Join(options.FlightPath, " "))
+
+	if useCache {
+		c.cache.Set(u, data, ttl)
 	}
-	if options.MinDegreeDistance != 0 {
-		q.Set("minDegreeDistance", strconv.FormatFloat(options.MinDegreeDistance, 'f', -1, 64))
+	return data, nil
+}
+
+// stream is like get but returns the response body unread, for decoding it while it arrives. Cached bodies are
+// served from the cache, but streamed bodies are not saved to it. The caller must close the body.
+func (c *client) stream(ctx context.Context, dataSource string, u string) (io.ReadCloser, error) {
+	if _, useCache := c.cacheTTLFor(dataSource); useCache {
+		if data, ok := c.cache.Get(u); ok {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		}
 	}
-	if len(options.Fields) > 0 {
-		q.Set("fields", strings.Join(options.Fields, " "))
+
+	var body io.ReadCloser
+	err := c.withRetries(ctx, func() (err error) {
+		body, err = c.open(ctx, u)
+		return
+	})
+	return body, err
+}
+
+// cacheTTLFor returns how long responses of dataSource are cached, and whether they are cached at all
+func (c *client) cacheTTLFor(dataSource string) (time.Duration, bool) {
+	ttl := c.cacheTTL
+	if t, ok := c.dataSourceTTL[dataSource]; ok {
+		ttl = t
 	}
+	return ttl, c.cache != nil && ttl > 0
+}
 
-	u.RawQuery = q.Encode()
+// withRetries calls attempt until it succeeds or the client's retry policy gives up
+func (c *client) withRetries(ctx context.Context, attempt func() error) error {
+	n := 1
+	for {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
 
-	httpResponse, err := http.Get(u.String())
-	if err != nil {
+		if n >= c.retryPolicy.MaxAttempts || !c.retryPolicy.shouldRetry(ctx, err) {
+			if n > 1 {
+				return &RetryError{Attempts: n, Err: err}
+			}
+			return err
+		}
+
+		n++
+		if sleepErr := sleep(ctx, c.retryPolicy.retryDelay(n, err)); sleepErr != nil {
+			return &RetryError{Attempts: n - 1, Err: fmt.Errorf("waiting to retry aborted: %w", sleepErr)}
+		}
+	}
+}
+
+// open sends a single GET request of u and returns the body of a successful response
+func (c *client) open(ctx context.Context, u string) (io.ReadCloser, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
 		return nil, err
 	}
 
-	data, err := ioutil.ReadAll(httpResponse.Body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
+	if len(c.userAgent) > 0 {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
-	var r tafs.Response
-	err = xml.Unmarshal(data, &r)
+	httpResponse, err := c.c.Do(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("request to %s aborted: %w", req.URL.Host, ctxErr)
+		}
 		return nil, err
+	}
 
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+		defer httpResponse.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(httpResponse.Body, maxErrorBodyLength))
+		return nil, newHTTPError(httpResponse, body)
 	}
 
-	return &r, nil
+	return httpResponse.Body, nil
 }
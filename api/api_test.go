@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stallingServer answers every request with the start of a METAR response when writeHeader is set, or nothing at
+// all, and then stalls until the client goes away
+func stallingServer(t *testing.T, writeHeader bool) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if writeHeader {
+			fmt.Fprint(w, metarResponse[:len(metarResponse)/2])
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name        string
+		writeHeader bool
+		wantPrefix  string
+	}{
+		{"while waiting for the response", false, "request to "},
+		{"while reading the body", true, "reading response aborted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(stallingServer(t, tt.writeHeader).URL)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			r, err := c.GetMetarContext(ctx, testMetarOptions)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("returned after %v, want soon after the deadline", elapsed)
+			}
+			if r != nil {
+				t.Errorf("got response %+v, want none", r)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+			}
+			if !strings.HasPrefix(err.Error(), tt.wantPrefix) {
+				t.Errorf("err = %q, want it to start with %q", err, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	c := NewClient(stallingServer(t, false).URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := c.GetTafContext(ctx, TafOptions{StationOptions: StationOptions{Stations: []string{"KORD"}}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/tafs"
+)
+
+// batching reports whether a request for stations is split into several requests, see WithBatching
+func (c *client) batching(stations []string) bool {
+	return c.batchSize > 0 && len(stations) > c.batchSize
+}
+
+// chunks removes duplicate stations and splits the rest into chunks of at most c.batchSize stations
+func (c *client) chunks(stations []string) [][]string {
+	seen := make(map[string]bool, len(stations))
+	var unique []string
+	for _, s := range stations {
+		key := strings.ToUpper(s)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, s)
+		}
+	}
+
+	var chunks [][]string
+	for len(unique) > c.batchSize {
+		chunks = append(chunks, unique[:c.batchSize:c.batchSize])
+		unique = unique[c.batchSize:]
+	}
+	return append(chunks, unique)
+}
+
+// runChunks calls fetch for every chunk from at most c.batchWorkers goroutines and collects the errors of the chunks
+// that failed. It returns nil when all chunks succeeded.
+func (c *client) runChunks(ctx context.Context, chunks [][]string, fetch func(ctx context.Context, i int) error) *BatchError {
+	workers := c.batchWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	errs := make([]error, len(chunks))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fetch(ctx, i)
+			}
+		}()
+	}
+	for i := range chunks {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var failed []*ChunkError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &ChunkError{Index: i, Stations: chunks[i], Err: err})
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{Total: len(chunks), Failed: failed}
+}
+
+// checkChunks calls check for every chunk in order and adds the chunks it fails to batchErr, which may be nil. It is
+// used to check the ADDS errors and warnings of each chunk's response so they keep the stations they were reported
+// for. The result is nil when no chunk failed.
+func checkChunks(chunks [][]string, batchErr *BatchError, check func(i int) error) *BatchError {
+	var failed []*ChunkError
+	if batchErr != nil {
+		failed = batchErr.Failed
+	}
+
+	for i := range chunks {
+		if err := check(i); err != nil {
+			failed = append(failed, &ChunkError{Index: i, Stations: chunks[i], Err: err})
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(failed, func(a, b int) bool { return failed[a].Index < failed[b].Index })
+	return &BatchError{Total: len(chunks), Failed: failed}
+}
+
+// getMetarsBatched requests the METARs of options.Stations in chunks and merges the responses. Reports found in more
+// than one response are only kept once. The merged response lists the errors and warnings of all chunks, when they
+// are turned into errors by WithADDSErrors each is returned as a ChunkError of a BatchError instead of one ADDSError.
+// Note that MostRecent applies to each chunk, not to the merged response.
+func (c *client) getMetarsBatched(ctx context.Context, options MetarOptions) (*metars.Response, error) {
+	chunks := c.chunks(options.Stations)
+	responses := make([]*metars.Response, len(chunks))
+	batchErr := c.runChunks(ctx, chunks, func(ctx context.Context, i int) (err error) {
+		o := options
+		o.Stations = chunks[i]
+		responses[i], err = c.getMetars(ctx, o)
+		return
+	})
+	batchErr = checkChunks(chunks, batchErr, func(i int) error {
+		if r := responses[i]; r != nil {
+			return c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+		}
+		return nil
+	})
+
+	var merged *metars.Response
+	seen := make(map[string]bool)
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		if merged == nil {
+			first := *r
+			first.Errors, first.Warnings, first.Data.Metars = nil, nil, nil
+			merged = &first
+		}
+		merged.Errors = appendUnique(merged.Errors, r.Errors)
+		merged.Warnings = appendUnique(merged.Warnings, r.Warnings)
+		if r.TimeTakenMs > merged.TimeTakenMs {
+			merged.TimeTakenMs = r.TimeTakenMs
+		}
+		for _, m := range r.Data.Metars {
+			key := m.StationId + "\x00" + m.RawText
+			if !seen[key] {
+				seen[key] = true
+				merged.Data.Metars = append(merged.Data.Metars, m)
+			}
+		}
+	}
+	if merged == nil {
+		return nil, batchErr
+	}
+	merged.Data.NumResults = int32(len(merged.Data.Metars))
+
+	if batchErr != nil {
+		return merged, batchErr
+	}
+	return merged, nil
+}
+
+// getTafsBatched requests the TAFs of options.Stations in chunks and merges the responses, see getMetarsBatched
+func (c *client) getTafsBatched(ctx context.Context, options TafOptions) (*tafs.Response, error) {
+	chunks := c.chunks(options.Stations)
+	responses := make([]*tafs.Response, len(chunks))
+	batchErr := c.runChunks(ctx, chunks, func(ctx context.Context, i int) (err error) {
+		o := options
+		o.Stations = chunks[i]
+		responses[i], err = c.getTafs(ctx, o)
+		return
+	})
+	batchErr = checkChunks(chunks, batchErr, func(i int) error {
+		if r := responses[i]; r != nil {
+			return c.checkADDS(r.RequestIndex, r.Errors, r.Warnings)
+		}
+		return nil
+	})
+
+	var merged *tafs.Response
+	seen := make(map[string]bool)
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		if merged == nil {
+			first := *r
+			first.Errors, first.Warnings, first.Data.Tafs = nil, nil, nil
+			merged = &first
+		}
+		merged.Errors = appendUnique(merged.Errors, r.Errors)
+		merged.Warnings = appendUnique(merged.Warnings, r.Warnings)
+		if r.TimeTakenMs > merged.TimeTakenMs {
+			merged.TimeTakenMs = r.TimeTakenMs
+		}
+		for _, t := range r.Data.Tafs {
+			key := t.StationId + "\x00" + t.RawText
+			if !seen[key] {
+				seen[key] = true
+				merged.Data.Tafs = append(merged.Data.Tafs, t)
+			}
+		}
+	}
+	if merged == nil {
+		return nil, batchErr
+	}
+	merged.Data.NumResults = int32(len(merged.Data.Tafs))
+
+	if batchErr != nil {
+		return merged, batchErr
+	}
+	return merged, nil
+}
+
+// appendUnique appends the messages of b that are not in a yet
+func appendUnique(a []string, b []string) []string {
+	for _, s := range b {
+		found := false
+		for _, t := range a {
+			if s == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, s)
+		}
+	}
+	return a
+}
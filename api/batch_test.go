@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// batchServer answers METAR requests with one METAR per requested station plus a KDUP METAR found in every response.
+// Requests for KBAD fail with a server error, requests for KERR report an ADDS error and every response carries the
+// same warning. It records the station list of every request.
+func batchServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stations := r.URL.Query().Get("stationString")
+		mu.Lock()
+		requests = append(requests, stations)
+		mu.Unlock()
+
+		if strings.Contains(stations, "KBAD") {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		var errs, metars strings.Builder
+		for _, station := range strings.Fields(stations) {
+			if station == "KERR" {
+				fmt.Fprintf(&errs, "<error>Invalid station string: %s</error>", station)
+				continue
+			}
+			fmt.Fprintf(&metars, "<METAR><raw_text>%s 181151Z 27015KT 10SM CLR 12/M03 A3012</raw_text><station_id>%s</station_id></METAR>", station, station)
+		}
+		metars.WriteString("<METAR><raw_text>KDUP 181151Z 00000KT 10SM CLR 10/05 A3000</raw_text><station_id>KDUP</station_id></METAR>")
+
+		fmt.Fprintf(w, "<response><request_index>7</request_index><errors>%s</errors><warnings><warning>Station list shortened</warning></warnings><data>%s</data></response>",
+			errs.String(), metars.String())
+	}))
+	t.Cleanup(s.Close)
+
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([]string{}, requests...)
+		sort.Strings(sorted)
+		return sorted
+	}
+}
+
+func batchOptions(stations ...string) MetarOptions {
+	return MetarOptions{StationOptions: StationOptions{Stations: stations}}
+}
+
+func TestBatchChunks(t *testing.T) {
+	s, requests := batchServer(t)
+	c := NewClient(s.URL, WithBatching(2, 2))
+
+	r, err := c.GetMetar(batchOptions("KAAA", "KBBB", "KCCC", "kaaa", "KDDD", "KEEE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// duplicate stations are removed before splitting, case-insensitively
+	if got, want := requests(), []string{"KAAA KBBB", "KCCC KDDD", "KEEE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	var got []string
+	for _, m := range r.Data.Metars {
+		got = append(got, m.StationId)
+	}
+	sort.Strings(got)
+	if want := []string{"KAAA", "KBBB", "KCCC", "KDDD", "KDUP", "KEEE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged METARs = %q, want %q with KDUP only once", got, want)
+	}
+	if r.Data.NumResults != int32(len(r.Data.Metars)) {
+		t.Errorf("num_results = %d, want %d", r.Data.NumResults, len(r.Data.Metars))
+	}
+	if !reflect.DeepEqual(r.Warnings, []string{"Station list shortened"}) {
+		t.Errorf("merged warnings = %q, want the warning of every chunk once", r.Warnings)
+	}
+}
+
+func TestBatchWithoutBatching(t *testing.T) {
+	s, requests := batchServer(t)
+	c := NewClient(s.URL, WithBatching(10, 2))
+
+	if _, err := c.GetMetar(batchOptions("KAAA", "KBBB", "KCCC")); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests(); len(got) != 1 {
+		t.Errorf("requests = %q, want a single request", got)
+	}
+}
+
+func TestBatchFailingChunk(t *testing.T) {
+	s, _ := batchServer(t)
+	c := NewClient(s.URL, WithBatching(2, 2))
+
+	r, err := c.GetMetar(batchOptions("KAAA", "KBBB", "KBAD", "KCCC", "KDDD"))
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err = %v, want *BatchError", err)
+	}
+	if batchErr.Total != 3 || len(batchErr.Failed) != 1 {
+		t.Fatalf("BatchError = %d of %d failed, want 1 of 3", len(batchErr.Failed), batchErr.Total)
+	}
+	failed := batchErr.Failed[0]
+	if failed.Index != 1 || !reflect.DeepEqual(failed.Stations, []string{"KBAD", "KCCC"}) {
+		t.Errorf("failed chunk = %d %q, want 1 [KBAD KCCC]", failed.Index, failed.Stations)
+	}
+	var httpErr *HTTPError
+	if !errors.As(failed, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("failed chunk error = %v, want the HTTP 500", failed.Err)
+	}
+
+	// the other chunks are still returned
+	if r == nil || len(r.Data.Metars) != 4 {
+		t.Fatalf("got %v, want the 4 METARs of KAAA, KBBB, KDDD and KDUP", r)
+	}
+}
+
+func TestBatchADDSErrorsPerChunk(t *testing.T) {
+	s, _ := batchServer(t)
+
+	var warnings []*ADDSWarnings
+	c := NewClient(s.URL, WithBatching(2, 2), WithADDSErrors(false), WithWarningHandler(func(w *ADDSWarnings) {
+		warnings = append(warnings, w)
+	}))
+
+	r, err := c.GetMetar(batchOptions("KAAA", "KBBB", "KCCC", "KERR", "KDDD"))
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err = %v, want *BatchError", err)
+	}
+	if len(batchErr.Failed) != 1 {
+		t.Fatalf("%d chunks failed, want only the chunk of KERR", len(batchErr.Failed))
+	}
+	failed := batchErr.Failed[0]
+	if failed.Index != 1 || !reflect.DeepEqual(failed.Stations, []string{"KCCC", "KERR"}) {
+		t.Errorf("failed chunk = %d %q, want 1 [KCCC KERR]", failed.Index, failed.Stations)
+	}
+	var addsErr *ADDSError
+	if !errors.As(failed, &addsErr) || !reflect.DeepEqual(addsErr.Messages, []string{"Invalid station string: KERR"}) {
+		t.Errorf("failed chunk error = %v, want the ADDS error of KERR", failed.Err)
+	}
+
+	// the warning handler is called for every chunk not failed by its errors
+	if len(warnings) != 2 {
+		t.Errorf("warning handler called %d times, want once for each of the other two chunks", len(warnings))
+	}
+
+	if r == nil || !reflect.DeepEqual(r.Errors, []string{"Invalid station string: KERR"}) {
+		t.Errorf("merged response = %v, want the ADDS error listed", r)
+	}
+}
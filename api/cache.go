@@ -0,0 +1,198 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores raw data server responses keyed by the canonical query URL. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	// Get returns the value stored for key, if it exists and has not expired
+	Get(key string) ([]byte, bool)
+	// Set stores value for key for the duration of ttl
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry once it holds capacity entries
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List // front is most recently used
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expires) {
+		m.lru.Remove(e)
+		delete(m.entries, key)
+		return nil, false
+	}
+
+	m.lru.MoveToFront(e)
+	return entry.value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if e, ok := m.entries[key]; ok {
+		entry := e.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expires = expires
+		m.lru.MoveToFront(e)
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryCacheEntry{key: key, value: value, expires: expires})
+	for m.lru.Len() > m.capacity {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// FileCache is a Cache that keeps one file per entry in a directory. Each file starts with the expiration time
+// as 8 bytes of big endian Unix nanoseconds followed by the cached value. Expired entries are removed when they are
+// read and by Prune, which NewFileCache runs so the directory does not keep growing from run to run.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache storing its entries in dir, which is created if it does not exist. Expired entries
+// already in dir are removed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	f := &FileCache{dir: dir}
+	if err := f.Prune(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Prune removes the expired entries. Long-running programs should call it from time to time, files that are not
+// cache entries are left alone.
+func (f *FileCache) Prune() error {
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, fi := range files {
+		if fi.IsDir() || len(fi.Name()) != 2*sha256.Size {
+			continue
+		}
+		if _, err := hex.DecodeString(fi.Name()); err != nil {
+			continue
+		}
+
+		p := filepath.Join(f.dir, fi.Name())
+		if expires, ok := readExpiration(p); ok && now.After(expires) {
+			os.Remove(p)
+		}
+	}
+	return nil
+}
+
+// readExpiration returns the expiration time from the header of the entry file p
+func readExpiration(p string) (time.Time, bool) {
+	file, err := os.Open(p)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer file.Close()
+
+	var header [8]byte
+	if _, err := io.ReadFull(file, header[:]); err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(header[:]))), true
+}
+
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:]))
+}
+
+func (f *FileCache) Get(key string) ([]byte, bool) {
+	p := f.path(key)
+	data, err := ioutil.ReadFile(p)
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(data[:8])))
+	if time.Now().After(expires) {
+		os.Remove(p)
+		return nil, false
+	}
+
+	return data[8:], true
+}
+
+// Set writes the entry to a temporary file first and renames it so concurrent readers never see partial entries.
+// Write failures are ignored, the entry is simply not cached.
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	tmp, err := ioutil.TempFile(f.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	var header [8]byte
+	binary.BigEndian.PutUint64(header[:], uint64(time.Now().Add(ttl).UnixNano()))
+
+	_, err = tmp.Write(header[:])
+	if err == nil {
+		_, err = tmp.Write(value)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	os.Rename(tmp.Name(), f.path(key))
+}
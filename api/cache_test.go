@@ -0,0 +1,179 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	m := NewMemoryCache(3)
+	for _, k := range []string{"a", "b", "c"} {
+		m.Set(k, []byte(k), time.Hour)
+	}
+
+	m.Get("a")                          // b is now the least recently used
+	m.Set("c", []byte("c2"), time.Hour) // updating c uses it too
+	m.Set("d", []byte("d"), time.Hour)  // evicts b
+	m.Set("e", []byte("e"), time.Hour)  // evicts a
+
+	for k, want := range map[string]string{"c": "c2", "d": "d", "e": "e"} {
+		if got, ok := m.Get(k); !ok || string(got) != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", k, got, ok, want)
+		}
+	}
+	for _, k := range []string{"a", "b"} {
+		if _, ok := m.Get(k); ok {
+			t.Errorf("Get(%q) found an entry that should have been evicted", k)
+		}
+	}
+	if n := m.lru.Len(); n != 3 || len(m.entries) != 3 {
+		t.Errorf("cache holds %d/%d entries, want 3", n, len(m.entries))
+	}
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	m := NewMemoryCache(10)
+	m.Set("short", []byte("x"), time.Millisecond)
+	m.Set("long", []byte("y"), time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := m.Get("short"); ok {
+		t.Error("Get returned an expired entry")
+	}
+	if _, ok := m.entries["short"]; ok {
+		t.Error("expired entry was not removed by Get")
+	}
+	if got, ok := m.Get("long"); !ok || string(got) != "y" {
+		t.Errorf("Get(long) = %q, %v, want y", got, ok)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewFileCache(filepath.Join(dir, "avwx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Set("http://example.invalid/?dataSource=metars", []byte("<response/>"), time.Hour)
+	if got, ok := f.Get("http://example.invalid/?dataSource=metars"); !ok || string(got) != "<response/>" {
+		t.Errorf("Get = %q, %v, want the stored value", got, ok)
+	}
+	if _, ok := f.Get("http://example.invalid/?dataSource=tafs"); ok {
+		t.Error("Get found an entry for a key that was never set")
+	}
+
+	f.Set("expired", []byte("old"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := f.Get("expired"); ok {
+		t.Error("Get returned an expired entry")
+	}
+	if _, err := os.Stat(f.path("expired")); !os.IsNotExist(err) {
+		t.Errorf("expired entry file still exists after Get: %v", err)
+	}
+
+	// NewFileCache prunes expired entries left by earlier runs and keeps everything else
+	f.Set("left over", []byte("old"), time.Millisecond)
+	if err := ioutil.WriteFile(filepath.Join(f.dir, "notes.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	f, err = NewFileCache(f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(f.path("left over")); !os.IsNotExist(err) {
+		t.Errorf("expired entry file still exists after NewFileCache: %v", err)
+	}
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("cache directory holds %d files, want the live entry and notes.txt", len(files))
+	}
+}
+
+func TestCacheTTLFor(t *testing.T) {
+	cache := NewMemoryCache(10)
+	tests := []struct {
+		name    string
+		opts    []ClientOption
+		source  string
+		wantTTL time.Duration
+		wantUse bool
+	}{
+		{"no cache", nil, "metars", 0, false},
+		{"cache ttl", []ClientOption{WithCache(cache, time.Minute)}, "metars", time.Minute, true},
+		{"cache without ttl", []ClientOption{WithCache(cache, 0)}, "metars", 0, false},
+		{"override", []ClientOption{WithCache(cache, time.Minute), WithCacheTTL("tafs", time.Hour)}, "tafs", time.Hour, true},
+		{"override other source", []ClientOption{WithCache(cache, time.Minute), WithCacheTTL("tafs", time.Hour)}, "metars", time.Minute, true},
+		{"override turns caching off", []ClientOption{WithCache(cache, time.Minute), WithCacheTTL("metars", 0)}, "metars", 0, false},
+		{"override only", []ClientOption{WithCache(cache, 0), WithCacheTTL("metars", time.Minute)}, "metars", time.Minute, true},
+		{"override without cache", []ClientOption{WithCacheTTL("metars", time.Minute)}, "metars", time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient("http://example.invalid/", tt.opts...).(*client)
+			if ttl, use := c.cacheTTLFor(tt.source); ttl != tt.wantTTL || use != tt.wantUse {
+				t.Errorf("cacheTTLFor(%q) = %v, %v, want %v, %v", tt.source, ttl, use, tt.wantTTL, tt.wantUse)
+			}
+		})
+	}
+}
+
+func TestCachedClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []ClientOption
+		wantRequests int32
+	}{
+		{"cached", nil, 1},
+		{"caching turned off for metars", []ClientOption{WithCacheTTL("metars", 0)}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, requests := failingServer(t, 0, 0, nil)
+			opts := append([]ClientOption{WithCache(NewMemoryCache(10), time.Hour)}, tt.opts...)
+			c := NewClient(s.URL, opts...)
+
+			for i := 0; i < 2; i++ {
+				r, err := c.GetMetar(testMetarOptions)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(r.Data.Metars) != 1 {
+					t.Errorf("request %d got %d METARs, want 1", i+1, len(r.Data.Metars))
+				}
+			}
+			if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCachedClientSkipsFailedResponses(t *testing.T) {
+	s, requests := failingServer(t, 1, http.StatusServiceUnavailable, nil)
+	c := NewClient(s.URL, WithCache(NewMemoryCache(10), time.Hour))
+
+	if _, err := c.GetMetar(testMetarOptions); err == nil {
+		t.Fatal("first request succeeded, want the 503")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMetar(testMetarOptions); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("requests = %d, want the failure not cached and the success cached", got)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClientOption configures a client created by NewClient
+type ClientOption func(*client)
+
+// WithHTTPClient makes the client send its requests through a copy of hc. Options applied after it (for example
+// WithTimeout or WithTransport) change the copy only, never the caller's http.Client.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *client) {
+		if hc == nil {
+			return
+		}
+		cp := *hc
+		c.c = &cp
+	}
+}
+
+// WithTransport sets the http.RoundTripper used for all requests, e.g. to route through a proxy or configure mTLS
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *client) {
+		c.c.Transport = rt
+	}
+}
+
+// WithTimeout limits the time a single HTTP request, including reading the response body, may take
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *client) {
+		c.c.Timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithADDSErrors makes the client return an *ADDSError, along with the decoded response, when the data server lists
+// errors in its response. If fatalWarnings is true, listed warnings are returned the same way as *ADDSWarnings.
+func WithADDSErrors(fatalWarnings bool) ClientOption {
+	return func(c *client) {
+		c.addsErrors = true
+		c.fatalWarnings = fatalWarnings
+	}
+}
+
+// WithWarningHandler registers a function that is called with the warnings of every response that has any
+func WithWarningHandler(handler func(*ADDSWarnings)) ClientOption {
+	return func(c *client) {
+		c.warningHandler = handler
+	}
+}
+
+// WithRetryPolicy makes the client retry failed requests according to policy
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests on average with bursts of up to burst requests
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *client) {
+		c.limiter = NewRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithRateLimiter makes the client use limiter, which may be shared with other clients
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *client) {
+		c.limiter = limiter
+	}
+}
+
+// WithCache makes the client look up responses in cache before sending a request and store successful responses in
+// it for ttl. Use WithCacheTTL to store responses of specific data sources for a different time.
+func WithCache(cache Cache, ttl time.Duration) ClientOption {
+	return func(c *client) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+// WithCacheTTL sets how long responses of dataSource (e.g. "metars" or "tafs") are cached, overriding the ttl given to
+// WithCache. A ttl of 0 disables caching for the data source.
+func WithCacheTTL(dataSource string, ttl time.Duration) ClientOption {
+	return func(c *client) {
+		if c.dataSourceTTL == nil {
+			c.dataSourceTTL = make(map[string]time.Duration)
+		}
+		c.dataSourceTTL[dataSource] = ttl
+	}
+}
+
+// WithBackend selects the web service the client talks to. The endpoint given to NewClient must belong to it, e.g.
+// DefaultDataApiEndPoint for BackendDataAPI.
+func WithBackend(backend Backend) ClientOption {
+	return func(c *client) {
+		c.backend = backend
+	}
+}
+
+// WithFormat selects the response format requested from the Text Data Server. FormatCSV is much smaller for bulk
+// requests and is used for METARs and TAFs, all other data sources are always requested as XML.
+func WithFormat(format Format) ClientOption {
+	return func(c *client) {
+		c.format = format
+	}
+}
+
+// WithBatching splits METAR and TAF requests for more than chunkSize stations into requests of at most chunkSize
+// stations, sent by up to workers goroutines at a time, and merges their responses. Long station lists otherwise make
+// URLs the data server rejects.
+func WithBatching(chunkSize int, workers int) ClientOption {
+	return func(c *client) {
+		c.batchSize = chunkSize
+		c.batchWorkers = workers
+	}
+}
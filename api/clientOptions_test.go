@@ -0,0 +1,90 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc lets a function serve as http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithHTTPClientKeepsCallersClient(t *testing.T) {
+	callerTransport := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		t.Error("request sent through the caller's transport")
+		return nil, http.ErrHandlerTimeout
+	})
+	hc := &http.Client{Transport: callerTransport, Timeout: time.Minute}
+
+	var requests int
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Body:       ioutil.NopCloser(strings.NewReader(metarResponse)),
+			Request:    r,
+		}, nil
+	})
+	c := NewClient("http://example.invalid/", WithHTTPClient(hc), WithTimeout(time.Second), WithTransport(transport))
+
+	if _, err := c.GetMetar(testMetarOptions); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("requests through WithTransport = %d, want 1", requests)
+	}
+	if hc.Timeout != time.Minute {
+		t.Errorf("caller's Timeout = %v, want it unchanged at 1m", hc.Timeout)
+	}
+	if _, ok := hc.Transport.(roundTripFunc); !ok {
+		t.Errorf("caller's Transport = %T, want it unchanged", hc.Transport)
+	}
+
+	if got := c.(*client).c; got == hc || got.Timeout != time.Second {
+		t.Errorf("client's http.Client = %p with Timeout %v, want a copy of %p with Timeout 1s", got, got.Timeout, hc)
+	}
+}
+
+func TestWithHTTPClientNil(t *testing.T) {
+	c := NewClient("http://example.invalid/", WithHTTPClient(nil), WithTimeout(time.Second))
+	if got := c.(*client).c; got == nil || got.Timeout != time.Second {
+		t.Errorf("client's http.Client = %+v, want the default with Timeout 1s", got)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{"set", []ClientOption{WithUserAgent("fleet-dashboard/1.2")}, "fleet-dashboard/1.2"},
+		{"default", nil, "Go-http-client/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+				w.Write([]byte(metarResponse))
+			}))
+			defer s.Close()
+
+			if _, err := NewClient(s.URL, tt.opts...).GetMetar(testMetarOptions); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/tafs"
+)
+
+// Format is the response format requested from the Text Data Server
+type Format string
+
+const (
+	FormatXML Format = "xml"
+	FormatCSV Format = "csv"
+)
+
+var (
+	csvTimeTakenPattern  = regexp.MustCompile(`^(\d+) ms$`)
+	csvNumResultsPattern = regexp.MustCompile(`^(\d+) results?$`)
+)
+
+// csvPreamble holds the lines the Text Data Server writes before the CSV header, e.g.
+//
+//	No errors
+//	No warnings
+//	5 ms
+//	data source=metars
+//	2 results
+type csvPreamble struct {
+	errors      []string
+	warnings    []string
+	timeTakenMs int32
+	dataSource  string
+	numResults  int32
+}
+
+// readCSVPreamble consumes the preamble from r, which is left positioned at the CSV header. It returns false if the
+// response has no CSV data, e.g. because the request failed.
+func readCSVPreamble(r *bufio.Reader) (p csvPreamble, hasData bool, err error) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return p, false, err
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || line == "No errors" || line == "No warnings":
+		case csvTimeTakenPattern.MatchString(line):
+			n, _ := strconv.ParseInt(csvTimeTakenPattern.FindStringSubmatch(line)[1], 10, 32)
+			p.timeTakenMs = int32(n)
+		case strings.HasPrefix(line, "data source="):
+			p.dataSource = strings.TrimPrefix(line, "data source=")
+		case csvNumResultsPattern.MatchString(line):
+			// the results count is the last line before the header
+			n, _ := strconv.ParseInt(csvNumResultsPattern.FindStringSubmatch(line)[1], 10, 32)
+			p.numResults = int32(n)
+			return p, true, nil
+		case strings.Contains(strings.ToLower(line), "warning"):
+			p.warnings = append(p.warnings, line)
+		default:
+			p.errors = append(p.errors, line)
+		}
+
+		if err == io.EOF {
+			return p, false, nil
+		}
+	}
+}
+
+// readCSVRecords reads the header and the records following the preamble. Records have different lengths because
+// repeated column groups, like sky conditions, are only written as far as they are used.
+func readCSVRecords(r io.Reader) (header []string, records [][]string, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	all, err := cr.ReadAll()
+	if err != nil || len(all) == 0 {
+		return nil, nil, err
+	}
+
+	return all[0], all[1:], nil
+}
+
+func decodeMetarsCSV(data []byte, resp *metars.Response) error {
+	br := bufio.NewReader(bytes.NewReader(data))
+	p, hasData, err := readCSVPreamble(br)
+	if err != nil {
+		return err
+	}
+
+	resp.Errors, resp.Warnings, resp.TimeTakenMs = p.errors, p.warnings, p.timeTakenMs
+	resp.DataSource.Name = p.dataSource
+	resp.Request.Type = "retrieve"
+	if !hasData {
+		return nil
+	}
+
+	header, records, err := readCSVRecords(br)
+	if err != nil {
+		return err
+	}
+
+	for i, rec := range records {
+		var m metars.Metar
+		var d csvFieldDecoder
+		for col, value := range rec {
+			if col >= len(header) || len(value) == 0 {
+				continue
+			}
+
+			switch header[col] {
+			case "raw_text":
+				m.RawText = value
+			case "station_id":
+				m.StationId = value
+			case "observation_time":
+				m.ObservationTime = d.time(value)
+			case "latitude":
+				m.Latitude = d.float(value)
+			case "longitude":
+				m.Longitude = d.float(value)
+			case "temp_c":
+				m.TempC = d.float(value)
+			case "dewpoint_c":
+				m.DewpointC = d.float(value)
+			case "wind_dir_degrees":
+				m.WindDirDegrees = d.int(value)
+			case "wind_speed_kt":
+				m.WindSpeedKt = d.int(value)
+			case "wind_gust_kt":
+				m.WindGustKt = d.int(value)
+			case "visibility_statute_mi":
+				m.VisibilityStatuteMi = d.float(strings.TrimSuffix(value, "+"))
+			case "altim_in_hg":
+				m.AltimInHg = d.float(value)
+			case "sea_level_pressure_mb":
+				m.SeaLevelPressureMb = d.float(value)
+			case "auto_station":
+				m.QualityControlFlags.AutoStation = d.bool(value)
+			case "wx_string":
+				m.WxString = value
+			case "sky_cover":
+				// every sky_cover column starts another sky condition
+				m.SkyCondition = append(m.SkyCondition, metars.SkyCondition{SkyCover: value})
+			case "cloud_base_ft_agl":
+				if n := len(m.SkyCondition); n > 0 {
+					m.SkyCondition[n-1].CloudBaseFtAGL = d.int(value)
+				}
+			case "flight_category":
+				m.FlightCategory = value
+			case "three_hr_pressure_tendency_mb":
+				m.ThreeHrPressureTendencyMb = d.float(value)
+			case "maxT_c":
+				m.MaxTC = d.float(value)
+			case "minT_c":
+				m.MinTC = d.float(value)
+			case "maxT24hr_c":
+				m.MaxT24hrC = d.float(value)
+			case "minT24hr_c":
+				m.MinT24hrC = d.float(value)
+			case "precip_in":
+				m.PrecipIn = d.float(value)
+			case "pcp3hr_in":
+				m.Pcp3hrIn = d.float(value)
+			case "pcp6hr_in":
+				m.Pcp6hrIn = d.float(value)
+			case "pcp24hr_in":
+				m.Pcp24hrIn = d.float(value)
+			case "snow_in":
+				m.SnowIn = d.float(value)
+			case "vert_vis_ft":
+				m.VertVisFt = d.int(value)
+			case "metar_type":
+				m.MetarType = value
+			case "elevation_m":
+				m.ElevationM = d.float(value)
+			}
+		}
+
+		if d.err != nil {
+			return fmt.Errorf("METAR CSV record %d: %v", i+1, d.err)
+		}
+		resp.Data.Metars = append(resp.Data.Metars, m)
+	}
+	resp.Data.NumResults = int32(len(resp.Data.Metars))
+
+	return nil
+}
+
+func decodeTafsCSV(data []byte, resp *tafs.Response) error {
+	br := bufio.NewReader(bytes.NewReader(data))
+	p, hasData, err := readCSVPreamble(br)
+	if err != nil {
+		return err
+	}
+
+	resp.Errors, resp.Warnings, resp.TimeTakenMs = p.errors, p.warnings, p.timeTakenMs
+	resp.DataSource.Name = p.dataSource
+	resp.Request.Type = "retrieve"
+	if !hasData {
+		return nil
+	}
+
+	header, records, err := readCSVRecords(br)
+	if err != nil {
+		return err
+	}
+
+	for i, rec := range records {
+		var t tafs.Taf
+		var d csvFieldDecoder
+
+		// forecast returns the forecast group the current column belongs to
+		forecast := func() *tafs.Forecast {
+			if len(t.Forecast) == 0 {
+				t.Forecast = append(t.Forecast, tafs.Forecast{})
+			}
+			return &t.Forecast[len(t.Forecast)-1]
+		}
+
+		for col, value := range rec {
+			if col >= len(header) {
+				continue
+			}
+
+			// every fcst_time_from column starts another forecast group, an empty one ends the groups in use
+			if header[col] == "fcst_time_from" {
+				if len(value) == 0 {
+					break
+				}
+				t.Forecast = append(t.Forecast, tafs.Forecast{})
+			}
+			if len(value) == 0 {
+				continue
+			}
+
+			switch header[col] {
+			case "raw_text":
+				t.RawText = value
+			case "station_id":
+				t.StationId = value
+			case "issue_time":
+				t.IssueTime = d.time(value)
+			case "bulletin_time":
+				t.BulletinTime = d.time(value)
+			case "valid_time_from":
+				t.ValidTimeFrom = d.time(value)
+			case "valid_time_to":
+				t.ValidTimeTo = d.time(value)
+			case "remarks":
+				t.Remarks = value
+			case "latitude":
+				t.Latitude = d.float(value)
+			case "longitude":
+				t.Longitude = d.float(value)
+			case "elevation_m":
+				t.ElevationM = d.float(value)
+			case "fcst_time_from":
+				forecast().FcstTimeFrom = d.time(value)
+			case "fcst_time_to":
+				forecast().FcstTimeTo = d.time(value)
+			case "change_indicator":
+				forecast().ChangeIndicator = value
+			case "time_becoming":
+				forecast().TimeBecoming = d.time(value)
+			case "probability":
+				forecast().Probability = d.int(value)
+			case "wind_dir_degrees":
+				forecast().WindDirDegrees = d.int(value)
+			case "wind_speed_kt":
+				forecast().WindSpeedKt = d.int(value)
+			case "wind_gust_kt":
+				forecast().WindGustKt = d.int(value)
+			case "wind_shear_hgt_ft_agl":
+				forecast().WindShearHgtFtAgl = d.int(value)
+			case "wind_shear_dir_degrees":
+				forecast().WindShearDirDegrees = d.int(value)
+			case "wind_shear_speed_kt":
+				forecast().WindShearSpeedKt = d.float(value)
+			case "visibility_statute_mi":
+				forecast().VisibilityStatuteMi = d.float(strings.TrimSuffix(value, "+"))
+			case "altim_in_hg":
+				forecast().AltimInHg = d.float(value)
+			case "vert_vis_ft":
+				forecast().VertVisFt = d.int(value)
+			case "wx_string":
+				forecast().WxString = value
+			case "not_decoded":
+				forecast().NotDecoded = value
+			case "sky_cover":
+				// every sky_cover, turbulence_intensity, icing_intensity and valid_time column starts another
+				// sky condition, layer or temperature of the forecast group
+				fc := forecast()
+				fc.SkyCondition = append(fc.SkyCondition, tafs.SkyCondition{SkyCover: value})
+			case "cloud_base_ft_agl":
+				if fc := forecast(); len(fc.SkyCondition) > 0 {
+					fc.SkyCondition[len(fc.SkyCondition)-1].CloudBaseFtAGL = d.int(value)
+				}
+			case "cloud_type":
+				if fc := forecast(); len(fc.SkyCondition) > 0 {
+					fc.SkyCondition[len(fc.SkyCondition)-1].CloudType = value
+				}
+			case "turbulence_intensity":
+				fc := forecast()
+				fc.TurbulenceCondition = append(fc.TurbulenceCondition, tafs.TurbulenceCondition{Intensity: value})
+			case "turbulence_min_alt_ft_agl":
+				if fc := forecast(); len(fc.TurbulenceCondition) > 0 {
+					fc.TurbulenceCondition[len(fc.TurbulenceCondition)-1].MinAltFtAGL = d.int(value)
+				}
+			case "turbulence_max_alt_ft_agl":
+				if fc := forecast(); len(fc.TurbulenceCondition) > 0 {
+					fc.TurbulenceCondition[len(fc.TurbulenceCondition)-1].MaxAltFtAGL = d.int(value)
+				}
+			case "icing_intensity":
+				fc := forecast()
+				fc.IcingCondition = append(fc.IcingCondition, tafs.IcingCondition{Intensity: value})
+			case "icing_min_alt_ft_agl":
+				if fc := forecast(); len(fc.IcingCondition) > 0 {
+					fc.IcingCondition[len(fc.IcingCondition)-1].MinAltFtAGL = d.int(value)
+				}
+			case "icing_max_alt_ft_agl":
+				if fc := forecast(); len(fc.IcingCondition) > 0 {
+					fc.IcingCondition[len(fc.IcingCondition)-1].MaxAltFtAGL = d.int(value)
+				}
+			case "valid_time":
+				fc := forecast()
+				fc.Temperature = append(fc.Temperature, tafs.Temperature{ValidTime: d.time(value)})
+			case "sfc_temp_c", "max_temp_c", "min_temp_c":
+				fc := forecast()
+				if len(fc.Temperature) == 0 {
+					break
+				}
+				c := d.float(value)
+				temp := &fc.Temperature[len(fc.Temperature)-1]
+				switch header[col] {
+				case "sfc_temp_c":
+					temp.SfcTempC = &c
+				case "max_temp_c":
+					temp.MaxTempC = &c
+				default:
+					temp.MinTempC = &c
+				}
+			}
+		}
+
+		if d.err != nil {
+			return fmt.Errorf("TAF CSV record %d: %v", i+1, d.err)
+		}
+		resp.Data.Tafs = append(resp.Data.Tafs, t)
+	}
+	resp.Data.NumResults = int32(len(resp.Data.Tafs))
+
+	return nil
+}
+
+// csvFieldDecoder converts CSV values and remembers the first conversion error
+type csvFieldDecoder struct {
+	err error
+}
+
+func (d *csvFieldDecoder) float(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return f
+}
+
+func (d *csvFieldDecoder) int(s string) int32 {
+	// some integer columns, e.g. cloud bases, are occasionally written with a decimal point
+	return int32(d.float(s))
+}
+
+func (d *csvFieldDecoder) bool(s string) bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return b
+}
+
+func (d *csvFieldDecoder) time(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return t
+}
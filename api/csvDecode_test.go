@@ -0,0 +1,125 @@
+package api
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/tafs"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDecodeMetarsCSV(t *testing.T) {
+	var r metars.Response
+	if err := decodeMetarsCSV(readTestdata(t, "adds_metars.csv"), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Errors) != 0 || len(r.Warnings) != 0 || r.TimeTakenMs != 4 || r.DataSource.Name != "metars" {
+		t.Errorf("preamble = errors %q, warnings %q, %d ms, data source %q", r.Errors, r.Warnings, r.TimeTakenMs, r.DataSource.Name)
+	}
+	if r.Data.NumResults != 2 || len(r.Data.Metars) != 2 {
+		t.Fatalf("got %d METARs (num_results %d), want 2", len(r.Data.Metars), r.Data.NumResults)
+	}
+
+	ord, sfo := r.Data.Metars[0], r.Data.Metars[1]
+	if ord.StationId != "KORD" || !ord.ObservationTime.Equal(time.Date(2026, 10, 18, 11, 51, 0, 0, time.UTC)) {
+		t.Errorf("KORD station/time = %s %v", ord.StationId, ord.ObservationTime)
+	}
+	if ord.VisibilityStatuteMi != 10 || ord.AltimInHg != 30.109253 || ord.WindGustKt != 24 {
+		t.Errorf("KORD visibility/altimeter/gust = %v/%v/%d", ord.VisibilityStatuteMi, ord.AltimInHg, ord.WindGustKt)
+	}
+	if !ord.QualityControlFlags.AutoStation || ord.MaxTC != 13.9 || ord.MinTC != 11.7 || ord.ElevationM != 201 {
+		t.Errorf("KORD auto station/max/min/elevation = %v/%v/%v/%v", ord.QualityControlFlags.AutoStation, ord.MaxTC, ord.MinTC, ord.ElevationM)
+	}
+	wantSky := []metars.SkyCondition{{SkyCover: "FEW", CloudBaseFtAGL: 5000}, {SkyCover: "BKN", CloudBaseFtAGL: 25000}}
+	if !reflect.DeepEqual(ord.SkyCondition, wantSky) {
+		t.Errorf("KORD sky = %+v, want %+v from the repeated sky_cover columns", ord.SkyCondition, wantSky)
+	}
+
+	if sfo.VisibilityStatuteMi != 0.5 || sfo.VertVisFt != 200 || sfo.WxString != "FG" || sfo.FlightCategory != "LIFR" {
+		t.Errorf("KSFO visibility/vertical visibility/weather/category = %v/%d/%s/%s", sfo.VisibilityStatuteMi, sfo.VertVisFt, sfo.WxString, sfo.FlightCategory)
+	}
+	if len(sfo.SkyCondition) != 1 || sfo.SkyCondition[0].SkyCover != "OVX" {
+		t.Errorf("KSFO sky = %+v, want OVX", sfo.SkyCondition)
+	}
+}
+
+func TestDecodeTafsCSV(t *testing.T) {
+	var r tafs.Response
+	if err := decodeTafsCSV(readTestdata(t, "adds_tafs.csv"), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.TimeTakenMs != 9 || r.DataSource.Name != "tafs" {
+		t.Errorf("preamble = %d ms, data source %q", r.TimeTakenMs, r.DataSource.Name)
+	}
+	if len(r.Data.Tafs) != 2 {
+		t.Fatalf("got %d TAFs, want 2", len(r.Data.Tafs))
+	}
+
+	den := r.Data.Tafs[0]
+	if den.StationId != "KDEN" || !den.ValidTimeTo.Equal(time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)) || den.ElevationM != 1640 {
+		t.Errorf("KDEN station/valid to/elevation = %s/%v/%v", den.StationId, den.ValidTimeTo, den.ElevationM)
+	}
+	if len(den.Forecast) != 3 {
+		t.Fatalf("KDEN has %d forecast groups, want 3 from the repeated fcst_time_from columns", len(den.Forecast))
+	}
+
+	base, from, tempo := den.Forecast[0], den.Forecast[1], den.Forecast[2]
+	wantSky := []tafs.SkyCondition{{SkyCover: "SCT", CloudBaseFtAGL: 8000}, {SkyCover: "BKN", CloudBaseFtAGL: 20000}}
+	if !reflect.DeepEqual(base.SkyCondition, wantSky) {
+		t.Errorf("base sky = %+v, want %+v", base.SkyCondition, wantSky)
+	}
+	if len(base.Temperature) != 1 || base.Temperature[0].MaxTempC == nil || *base.Temperature[0].MaxTempC != 24 || base.Temperature[0].MinTempC != nil {
+		t.Errorf("base temperature = %+v, want a maximum of 24", base.Temperature)
+	}
+
+	if from.ChangeIndicator != "FM" || from.WindGustKt != 25 || from.AltimInHg != 29.920275 || from.WxString != "VCSH" {
+		t.Errorf("FM change/gust/altimeter/weather = %s/%d/%v/%s", from.ChangeIndicator, from.WindGustKt, from.AltimInHg, from.WxString)
+	}
+	if len(from.SkyCondition) != 1 || from.SkyCondition[0].CloudType != "CB" {
+		t.Errorf("FM sky = %+v, want BKN060CB", from.SkyCondition)
+	}
+
+	wantTurb := []tafs.TurbulenceCondition{{Intensity: "2", MinAltFtAGL: 1000, MaxAltFtAGL: 7000}}
+	if tempo.ChangeIndicator != "TEMPO" || !reflect.DeepEqual(tempo.TurbulenceCondition, wantTurb) || len(tempo.IcingCondition) != 0 {
+		t.Errorf("TEMPO change/turbulence/icing = %s/%+v/%+v", tempo.ChangeIndicator, tempo.TurbulenceCondition, tempo.IcingCondition)
+	}
+
+	// the NIL TAF's row ends with empty forecast columns
+	sea := r.Data.Tafs[1]
+	if sea.StationId != "KSEA" || sea.Remarks != "AMD" || len(sea.Forecast) != 0 {
+		t.Errorf("KSEA station/remarks/forecasts = %s/%s/%d, want no forecast groups", sea.StationId, sea.Remarks, len(sea.Forecast))
+	}
+}
+
+func TestDecodeCSVPreamble(t *testing.T) {
+	data := []byte("Query must be constrained by time\nWarning: station list truncated\n3 ms\ndata source=metars\n")
+
+	var r metars.Response
+	if err := decodeMetarsCSV(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Errors, []string{"Query must be constrained by time"}) {
+		t.Errorf("Errors = %q", r.Errors)
+	}
+	if !reflect.DeepEqual(r.Warnings, []string{"Warning: station list truncated"}) {
+		t.Errorf("Warnings = %q", r.Warnings)
+	}
+	if r.TimeTakenMs != 3 || r.DataSource.Name != "metars" || len(r.Data.Metars) != 0 {
+		t.Errorf("time/data source/METARs = %d/%s/%d", r.TimeTakenMs, r.DataSource.Name, len(r.Data.Metars))
+	}
+}
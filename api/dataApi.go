@@ -0,0 +1,389 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/tafs"
+)
+
+const DefaultDataApiEndPoint = "https://aviationweather.gov/api/data"
+
+// Backend selects the web service a client talks to
+type Backend int
+
+const (
+	// BackendADDS is the legacy Text Data Server (ADDS) answering XML at DefaultApiEndPoint
+	BackendADDS Backend = iota
+	// BackendDataAPI is the JSON Data API at DefaultDataApiEndPoint. It serves METARs and TAFs only and does not
+	// support radial distance, flight path, minimum degree distance, most recent and fields constraints.
+	BackendDataAPI
+)
+
+const hPaToInHg = 0.0295299830714
+
+// dataApiMetar is a METAR as returned by the Data API's /metar endpoint
+type dataApiMetar struct {
+	IcaoId    string          `json:"icaoId"`
+	ObsTime   int64           `json:"obsTime"`
+	Temp      *float64        `json:"temp"`
+	Dewp      *float64        `json:"dewp"`
+	Wdir      json.RawMessage `json:"wdir"`
+	Wspd      *int32          `json:"wspd"`
+	Wgst      *int32          `json:"wgst"`
+	Visib     json.RawMessage `json:"visib"`
+	Altim     *float64        `json:"altim"`
+	Slp       *float64        `json:"slp"`
+	WxString  *string         `json:"wxString"`
+	PresTend  *float64        `json:"presTend"`
+	MaxT      *float64        `json:"maxT"`
+	MinT      *float64        `json:"minT"`
+	MaxT24    *float64        `json:"maxT24"`
+	MinT24    *float64        `json:"minT24"`
+	Precip    *float64        `json:"precip"`
+	Pcp3hr    *float64        `json:"pcp3hr"`
+	Pcp6hr    *float64        `json:"pcp6hr"`
+	Pcp24hr   *float64        `json:"pcp24hr"`
+	Snow      *float64        `json:"snow"`
+	VertVis   *int32          `json:"vertVis"`
+	MetarType string          `json:"metarType"`
+	RawOb     string          `json:"rawOb"`
+	Lat       float64         `json:"lat"`
+	Lon       float64         `json:"lon"`
+	Elev      float64         `json:"elev"`
+	FltCat    string          `json:"fltCat"`
+	Clouds    []struct {
+		Cover string `json:"cover"`
+		Base  *int32 `json:"base"`
+	} `json:"clouds"`
+}
+
+// dataApiTaf is a TAF as returned by the Data API's /taf endpoint
+type dataApiTaf struct {
+	IcaoId        string  `json:"icaoId"`
+	BulletinTime  string  `json:"bulletinTime"`
+	IssueTime     string  `json:"issueTime"`
+	ValidTimeFrom int64   `json:"validTimeFrom"`
+	ValidTimeTo   int64   `json:"validTimeTo"`
+	RawTAF        string  `json:"rawTAF"`
+	Remarks       string  `json:"remarks"`
+	Lat           float64 `json:"lat"`
+	Lon           float64 `json:"lon"`
+	Elev          float64 `json:"elev"`
+	Fcsts         []struct {
+		TimeFrom    int64           `json:"timeFrom"`
+		TimeTo      int64           `json:"timeTo"`
+		TimeBec     *int64          `json:"timeBec"`
+		FcstChange  *string         `json:"fcstChange"`
+		Probability *int32          `json:"probability"`
+		Wdir        json.RawMessage `json:"wdir"`
+		Wspd        *int32          `json:"wspd"`
+		Wgst        *int32          `json:"wgst"`
+		WshearHgt   *int32          `json:"wshearHgt"`
+		WshearDir   *int32          `json:"wshearDir"`
+		WshearSpd   *float64        `json:"wshearSpd"`
+		Visib       json.RawMessage `json:"visib"`
+		Altim       *float64        `json:"altim"`
+		VertVis     *int32          `json:"vertVis"`
+		WxString    *string         `json:"wxString"`
+		NotDecoded  *string         `json:"notDecoded"`
+		Clouds      []struct {
+			Cover string  `json:"cover"`
+			Base  *int32  `json:"base"`
+			Type  *string `json:"type"`
+		} `json:"clouds"`
+		IcgTurb []struct {
+			Var       string `json:"var"` // ICE or TURB
+			Intensity *int32 `json:"intensity"`
+			MinAlt    *int32 `json:"minAlt"`
+			MaxAlt    *int32 `json:"maxAlt"`
+		} `json:"icgTurb"`
+	} `json:"fcsts"`
+}
+
+// dataApiValues translates the options shared by METAR and TAF requests into Data API parameters
+func dataApiValues(q QueryOptions, s StationOptions) (url.Values, error) {
+	if q.RadialDistance != nil || q.FlightPath != nil || s.MinDegreeDistance != 0 ||
+		q.MostRecent || s.MostRecentForEachStation || len(q.Fields) > 0 {
+		return nil, errors.New("radialDistance, flightPath, minDegreeDistance, mostRecent, mostRecentForEachStation and fields are not supported by the data API backend")
+	}
+
+	v := url.Values{}
+	v.Set("format", "json")
+	if len(s.Stations) > 0 {
+		v.Set("ids", strings.Join(s.Stations, ","))
+	}
+
+	hours := q.HoursBeforeNow
+	start, end := time.Time(q.StartTime), time.Time(q.EndTime)
+	if !start.IsZero() && !end.IsZero() {
+		// the data API selects a number of hours before a date instead of a time range
+		v.Set("date", end.UTC().Format("2006-01-02T15:04:05Z"))
+		hours = int32(math.Ceil(end.Sub(start).Hours()))
+	}
+	setInt(v, "hours", hours)
+
+	if q.MinLat != nil && q.MaxLat != nil && q.MinLon != nil && q.MaxLon != nil {
+		v.Set("bbox", strings.Join([]string{formatFloat(*q.MinLat), formatFloat(*q.MinLon), formatFloat(*q.MaxLat), formatFloat(*q.MaxLon)}, ","))
+	}
+
+	return v, nil
+}
+
+// getDataApi requests path from the Data API and decodes the JSON array it returns into v
+func (c *client) getDataApi(ctx context.Context, dataSource string, path string, values url.Values, v interface{}) error {
+	u, err := url.Parse(strings.TrimSuffix(c.ApiEndPoint, "/") + path)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = values.Encode()
+
+	data, err := c.get(ctx, dataSource, u.String())
+	if err != nil {
+		return err
+	}
+
+	// no matches are reported with an empty body
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (c *client) getDataApiMetars(ctx context.Context, options MetarOptions) (*metars.Response, error) {
+	values, err := dataApiValues(options.QueryOptions, options.StationOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []dataApiMetar
+	if err := c.getDataApi(ctx, options.DataSource(), "/metar", values, &results); err != nil {
+		return nil, err
+	}
+
+	r := &metars.Response{
+		DataSource: metars.DataSource{Name: options.DataSource()},
+		Request:    metars.Request{Type: "retrieve"},
+	}
+	for _, m := range results {
+		r.Data.Metars = append(r.Data.Metars, m.toMetar())
+	}
+	r.Data.NumResults = int32(len(r.Data.Metars))
+
+	return r, nil
+}
+
+func (c *client) getDataApiTafs(ctx context.Context, options TafOptions) (*tafs.Response, error) {
+	values, err := dataApiValues(options.QueryOptions, options.StationOptions)
+	if err != nil {
+		return nil, err
+	}
+	setString(values, "time", options.TimeType)
+
+	var results []dataApiTaf
+	if err := c.getDataApi(ctx, options.DataSource(), "/taf", values, &results); err != nil {
+		return nil, err
+	}
+
+	r := &tafs.Response{
+		DataSource: tafs.DataSource{Name: options.DataSource()},
+		Request:    tafs.Request{Type: "retrieve"},
+	}
+	for _, t := range results {
+		r.Data.Tafs = append(r.Data.Tafs, t.toTaf())
+	}
+	r.Data.NumResults = int32(len(r.Data.Tafs))
+
+	return r, nil
+}
+
+func (m *dataApiMetar) toMetar() metars.Metar {
+	metar := metars.Metar{
+		RawText:                   m.RawOb,
+		StationId:                 m.IcaoId,
+		ObservationTime:           time.Unix(m.ObsTime, 0).UTC(),
+		Latitude:                  m.Lat,
+		Longitude:                 m.Lon,
+		TempC:                     derefFloat(m.Temp),
+		DewpointC:                 derefFloat(m.Dewp),
+		WindDirDegrees:            windDirection(m.Wdir),
+		WindSpeedKt:               derefInt(m.Wspd),
+		WindGustKt:                derefInt(m.Wgst),
+		VisibilityStatuteMi:       visibility(m.Visib),
+		SeaLevelPressureMb:        derefFloat(m.Slp),
+		WxString:                  derefString(m.WxString),
+		FlightCategory:            m.FltCat,
+		ThreeHrPressureTendencyMb: derefFloat(m.PresTend),
+		MaxTC:                     derefFloat(m.MaxT),
+		MinTC:                     derefFloat(m.MinT),
+		MaxT24hrC:                 derefFloat(m.MaxT24),
+		MinT24hrC:                 derefFloat(m.MinT24),
+		PrecipIn:                  derefFloat(m.Precip),
+		Pcp3hrIn:                  derefFloat(m.Pcp3hr),
+		Pcp6hrIn:                  derefFloat(m.Pcp6hr),
+		Pcp24hrIn:                 derefFloat(m.Pcp24hr),
+		SnowIn:                    derefFloat(m.Snow),
+		VertVisFt:                 derefInt(m.VertVis),
+		MetarType:                 m.MetarType,
+		ElevationM:                m.Elev,
+	}
+
+	if m.Altim != nil {
+		metar.AltimInHg = math.Round(*m.Altim*hPaToInHg*100) / 100
+	}
+	metar.QualityControlFlags.AutoStation = strings.Contains(m.RawOb, " AUTO ")
+
+	for _, cloud := range m.Clouds {
+		metar.SkyCondition = append(metar.SkyCondition, metars.SkyCondition{SkyCover: cloud.Cover, CloudBaseFtAGL: derefInt(cloud.Base)})
+	}
+
+	return metar
+}
+
+func (t *dataApiTaf) toTaf() tafs.Taf {
+	taf := tafs.Taf{
+		RawText:       t.RawTAF,
+		StationId:     t.IcaoId,
+		IssueTime:     dataApiTime(t.IssueTime),
+		BulletinTime:  dataApiTime(t.BulletinTime),
+		ValidTimeFrom: time.Unix(t.ValidTimeFrom, 0).UTC(),
+		ValidTimeTo:   time.Unix(t.ValidTimeTo, 0).UTC(),
+		Remarks:       t.Remarks,
+		Latitude:      t.Lat,
+		Longitude:     t.Lon,
+		ElevationM:    t.Elev,
+	}
+
+	for _, f := range t.Fcsts {
+		forecast := tafs.Forecast{
+			FcstTimeFrom:        time.Unix(f.TimeFrom, 0).UTC(),
+			FcstTimeTo:          time.Unix(f.TimeTo, 0).UTC(),
+			ChangeIndicator:     derefString(f.FcstChange),
+			Probability:         derefInt(f.Probability),
+			WindDirDegrees:      windDirection(f.Wdir),
+			WindSpeedKt:         derefInt(f.Wspd),
+			WindGustKt:          derefInt(f.Wgst),
+			WindShearHgtFtAgl:   derefInt(f.WshearHgt),
+			WindShearDirDegrees: derefInt(f.WshearDir),
+			WindShearSpeedKt:    derefFloat(f.WshearSpd),
+			VisibilityStatuteMi: visibility(f.Visib),
+			WxString:            derefString(f.WxString),
+			NotDecoded:          derefString(f.NotDecoded),
+		}
+		if f.TimeBec != nil {
+			forecast.TimeBecoming = time.Unix(*f.TimeBec, 0).UTC()
+		}
+		if f.Altim != nil {
+			forecast.AltimInHg = math.Round(*f.Altim*hPaToInHg*100) / 100
+		}
+		forecast.VertVisFt = derefInt(f.VertVis)
+		for _, cloud := range f.Clouds {
+			forecast.SkyCondition = append(forecast.SkyCondition, tafs.SkyCondition{
+				SkyCover:       cloud.Cover,
+				CloudBaseFtAGL: derefInt(cloud.Base),
+				CloudType:      derefString(cloud.Type),
+			})
+		}
+		for _, layer := range f.IcgTurb {
+			intensity := strconv.Itoa(int(derefInt(layer.Intensity)))
+			if layer.Var == "ICE" {
+				forecast.IcingCondition = append(forecast.IcingCondition, tafs.IcingCondition{
+					Intensity:   intensity,
+					MinAltFtAGL: derefInt(layer.MinAlt),
+					MaxAltFtAGL: derefInt(layer.MaxAlt),
+				})
+			} else {
+				forecast.TurbulenceCondition = append(forecast.TurbulenceCondition, tafs.TurbulenceCondition{
+					Intensity:   intensity,
+					MinAltFtAGL: derefInt(layer.MinAlt),
+					MaxAltFtAGL: derefInt(layer.MaxAlt),
+				})
+			}
+		}
+
+		taf.Forecast = append(taf.Forecast, forecast)
+	}
+
+	return taf
+}
+
+// windDirection decodes a wind direction given as degrees or as "VRB", which is reported as 0 like ADDS does
+func windDirection(raw json.RawMessage) int32 {
+	var degrees int32
+	if json.Unmarshal(raw, &degrees) == nil {
+		return degrees
+	}
+	return 0
+}
+
+// visibility decodes a visibility given in statute miles either as a number or as a string like "10+" or "1/2"
+func visibility(raw json.RawMessage) float64 {
+	var miles float64
+	if json.Unmarshal(raw, &miles) == nil {
+		return miles
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return 0
+	}
+	s = strings.TrimSuffix(strings.TrimSpace(s), "+")
+
+	if parts := strings.SplitN(s, "/", 2); len(parts) == 2 {
+		n, nErr := strconv.ParseFloat(parts[0], 64)
+		d, dErr := strconv.ParseFloat(parts[1], 64)
+		if nErr == nil && dErr == nil && d != 0 {
+			return n / d
+		}
+		return 0
+	}
+
+	miles, _ = strconv.ParseFloat(s, 64)
+	return miles
+}
+
+// dataApiTime parses the textual times of the data API, which come with or without a "T" and zone designator
+func dataApiTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02T15:04:05.000Z"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+func derefFloat(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+func derefInt(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// errDataApiUnsupported is returned for data sources the data API backend does not implement
+func errDataApiUnsupported(dataSource string) error {
+	return fmt.Errorf("data source %s is not supported by the data API backend", dataSource)
+}
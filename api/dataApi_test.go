@@ -0,0 +1,184 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dataApiServer serves the fixtures testdata/dataapi_<endpoint>.json and records the query of the last request
+func dataApiServer(t *testing.T) (*httptest.Server, *url.Values) {
+	t.Helper()
+
+	var query url.Values
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		b, err := ioutil.ReadFile(filepath.Join("testdata", "dataapi_"+strings.TrimPrefix(r.URL.Path, "/")+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+	}))
+	t.Cleanup(s.Close)
+
+	return s, &query
+}
+
+func TestDataApiMetars(t *testing.T) {
+	s, query := dataApiServer(t)
+	c := NewClient(s.URL, WithBackend(BackendDataAPI))
+
+	r, err := c.GetMetar(MetarOptions{
+		QueryOptions:   QueryOptions{HoursBeforeNow: 2},
+		StationOptions: StationOptions{Stations: []string{"KORD", "KSFO", "KXYZ"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := query.Encode(), "format=json&hours=2&ids=KORD%2CKSFO%2CKXYZ"; got != want {
+		t.Errorf("query = %s, want %s", got, want)
+	}
+	if r.Data.NumResults != 3 || len(r.Data.Metars) != 3 {
+		t.Fatalf("got %d METARs (num_results %d), want 3", len(r.Data.Metars), r.Data.NumResults)
+	}
+
+	ord, sfo, xyz := r.Data.Metars[0], r.Data.Metars[1], r.Data.Metars[2]
+
+	if ord.StationId != "KORD" || !ord.ObservationTime.Equal(time.Date(2026, 10, 18, 11, 51, 0, 0, time.UTC)) {
+		t.Errorf("KORD station/time = %s %v", ord.StationId, ord.ObservationTime)
+	}
+	if ord.WindDirDegrees != 270 || ord.WindSpeedKt != 15 || ord.WindGustKt != 24 {
+		t.Errorf("KORD wind = %d@%dG%d, want 270@15G24", ord.WindDirDegrees, ord.WindSpeedKt, ord.WindGustKt)
+	}
+	if ord.VisibilityStatuteMi != 10 {
+		t.Errorf(`KORD visibility "10+" = %v, want 10`, ord.VisibilityStatuteMi)
+	}
+	if ord.AltimInHg != 30.11 {
+		t.Errorf("KORD altimeter 1019.6 hPa = %v inHg, want 30.11", ord.AltimInHg)
+	}
+	if ord.TempC != 12.2 || ord.DewpointC != -2.8 || ord.SeaLevelPressureMb != 1019.4 {
+		t.Errorf("KORD temp/dewpoint/SLP = %v/%v/%v", ord.TempC, ord.DewpointC, ord.SeaLevelPressureMb)
+	}
+	if len(ord.SkyCondition) != 1 || ord.SkyCondition[0].SkyCover != "FEW" || ord.SkyCondition[0].CloudBaseFtAGL != 25000 {
+		t.Errorf("KORD sky = %+v, want FEW250", ord.SkyCondition)
+	}
+	if ord.QualityControlFlags.AutoStation {
+		t.Error("KORD is not an automated station")
+	}
+
+	if sfo.WindDirDegrees != 0 || sfo.WindSpeedKt != 3 {
+		t.Errorf(`KSFO wind "VRB" = %d@%d, want 0@3`, sfo.WindDirDegrees, sfo.WindSpeedKt)
+	}
+	if sfo.VisibilityStatuteMi != 0.5 {
+		t.Errorf(`KSFO visibility "1/2" = %v, want 0.5`, sfo.VisibilityStatuteMi)
+	}
+	if sfo.AltimInHg != 29.92 || sfo.VertVisFt != 200 || sfo.WxString != "FG" || sfo.FlightCategory != "LIFR" {
+		t.Errorf("KSFO altimeter/vertical visibility/weather/category = %v/%d/%s/%s", sfo.AltimInHg, sfo.VertVisFt, sfo.WxString, sfo.FlightCategory)
+	}
+	if !sfo.QualityControlFlags.AutoStation {
+		t.Error("KSFO is an automated station")
+	}
+
+	// missing fields decode as zero values like ADDS leaves out empty elements
+	if xyz.MetarType != "SPECI" || xyz.RawText != "KXYZ 181150Z AUTO M" {
+		t.Errorf("KXYZ type/raw text = %s/%s", xyz.MetarType, xyz.RawText)
+	}
+	if xyz.TempC != 0 || xyz.WindDirDegrees != 0 || xyz.VisibilityStatuteMi != 0 || xyz.AltimInHg != 0 || len(xyz.SkyCondition) != 0 {
+		t.Errorf("KXYZ missing fields = %+v", xyz)
+	}
+}
+
+func TestDataApiTafs(t *testing.T) {
+	s, query := dataApiServer(t)
+	c := NewClient(s.URL, WithBackend(BackendDataAPI))
+
+	r, err := c.GetTaf(TafOptions{
+		QueryOptions:   QueryOptions{StartTime: testTime("2026-10-18T06:00:00Z"), EndTime: testTime("2026-10-18T12:00:00Z")},
+		StationOptions: StationOptions{Stations: []string{"KDEN"}},
+		TimeType:       "issue",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := query.Encode(), "date=2026-10-18T12%3A00%3A00Z&format=json&hours=6&ids=KDEN&time=issue"; got != want {
+		t.Errorf("query = %s, want %s", got, want)
+	}
+	if len(r.Data.Tafs) != 1 {
+		t.Fatalf("got %d TAFs, want 1", len(r.Data.Tafs))
+	}
+
+	taf := r.Data.Tafs[0]
+	if !taf.IssueTime.Equal(time.Date(2026, 10, 18, 11, 20, 0, 0, time.UTC)) || !taf.BulletinTime.Equal(taf.IssueTime) {
+		t.Errorf("issue/bulletin time = %v/%v", taf.IssueTime, taf.BulletinTime)
+	}
+	if !taf.ValidTimeFrom.Equal(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)) || !taf.ValidTimeTo.Equal(time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("valid time = %v..%v", taf.ValidTimeFrom, taf.ValidTimeTo)
+	}
+	if len(taf.Forecast) != 3 {
+		t.Fatalf("got %d forecasts, want 3", len(taf.Forecast))
+	}
+
+	base, from, tempo := taf.Forecast[0], taf.Forecast[1], taf.Forecast[2]
+	if base.WindDirDegrees != 0 || base.WindSpeedKt != 5 || base.VisibilityStatuteMi != 6 || base.AltimInHg != 0 {
+		t.Errorf("base wind/visibility/altimeter = %d@%d/%v/%v, want 0@5/6/0", base.WindDirDegrees, base.WindSpeedKt, base.VisibilityStatuteMi, base.AltimInHg)
+	}
+	if len(base.SkyCondition) != 2 || base.SkyCondition[1].CloudBaseFtAGL != 20000 {
+		t.Errorf("base sky = %+v", base.SkyCondition)
+	}
+
+	if from.ChangeIndicator != "FM" || from.WindGustKt != 25 || from.AltimInHg != 29.92 || from.WxString != "VCSH" {
+		t.Errorf("FM change/gust/altimeter/weather = %s/%d/%v/%s", from.ChangeIndicator, from.WindGustKt, from.AltimInHg, from.WxString)
+	}
+	if len(from.SkyCondition) != 1 || from.SkyCondition[0].CloudType != "CB" {
+		t.Errorf("FM sky = %+v, want BKN060CB", from.SkyCondition)
+	}
+
+	if tempo.ChangeIndicator != "TEMPO" || tempo.VisibilityStatuteMi != 3 || tempo.WindSpeedKt != 0 {
+		t.Errorf("TEMPO change/visibility/wind = %s/%v/%d", tempo.ChangeIndicator, tempo.VisibilityStatuteMi, tempo.WindSpeedKt)
+	}
+	if len(tempo.TurbulenceCondition) != 1 || len(tempo.IcingCondition) != 0 {
+		t.Fatalf("TEMPO turbulence/icing = %+v/%+v", tempo.TurbulenceCondition, tempo.IcingCondition)
+	}
+	if turb := tempo.TurbulenceCondition[0]; turb.Intensity != "2" || turb.MinAltFtAGL != 1000 || turb.MaxAltFtAGL != 7000 {
+		t.Errorf("TEMPO turbulence = %+v, want intensity 2 from 1000 to 7000ft", turb)
+	}
+}
+
+func TestDataApiUnsupportedOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options MetarOptions
+	}{
+		{"radialDistance", MetarOptions{QueryOptions: QueryOptions{RadialDistance: &RadialQuery{RadiusSM: 20, Lat: 39.5, Lon: -104.5}}}},
+		{"flightPath", MetarOptions{QueryOptions: QueryOptions{FlightPath: &FlightPathQuery{MaxDistSM: 50, Waypoints: []Waypoint{{StationId: "KSEA"}, {StationId: "KDEN"}}}}}},
+		{"minDegreeDistance", MetarOptions{StationOptions: StationOptions{MinDegreeDistance: 1}}},
+		{"mostRecent", MetarOptions{QueryOptions: QueryOptions{MostRecent: true}}},
+		{"mostRecentForEachStation", MetarOptions{StationOptions: StationOptions{MostRecentForEachStation: true}}},
+		{"fields", MetarOptions{QueryOptions: QueryOptions{Fields: []string{"raw_text"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte("[]"))
+			}))
+			defer s.Close()
+
+			c := NewClient(s.URL, WithBackend(BackendDataAPI))
+			if _, err := c.GetMetar(tt.options); err == nil || !strings.Contains(err.Error(), "not supported by the data API backend") {
+				t.Errorf("err = %v, want the data API to reject %s", err, tt.name)
+			}
+			if requests != 0 {
+				t.Errorf("sent %d requests, want none", requests)
+			}
+		})
+	}
+}
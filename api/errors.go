@@ -0,0 +1,138 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBodyLength is the number of response body bytes kept in an HTTPError
+const maxErrorBodyLength = 512
+
+// HTTPError is returned when the data server answers with a non-2xx status code
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	URL        string
+	Body       string        // first maxErrorBodyLength bytes of the response body
+	RetryAfter time.Duration // wait time requested by the server's Retry-After header, 0 if none
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %s from %s", e.Status, e.URL)
+}
+
+func newHTTPError(r *http.Response, body []byte) *HTTPError {
+	if len(body) > maxErrorBodyLength {
+		body = body[:maxErrorBodyLength]
+	}
+
+	return &HTTPError{
+		StatusCode: r.StatusCode,
+		Status:     r.Status,
+		URL:        r.Request.URL.String(),
+		Body:       string(body),
+		RetryAfter: retryAfter(r.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// retryAfter decodes a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(value string, now time.Time) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// ADDSError is returned, when enabled with WithADDSErrors, if the data server lists errors in its response
+type ADDSError struct {
+	RequestIndex int32
+	Messages     []string
+}
+
+func (e *ADDSError) Error() string {
+	return "ADDS error(s): " + strings.Join(e.Messages, "\n")
+}
+
+// ADDSWarnings holds the warnings the data server listed in its response. It is passed to the handler set with
+// WithWarningHandler, or returned as an error when WithADDSErrors is used with fatal warnings.
+type ADDSWarnings struct {
+	RequestIndex int32
+	Messages     []string
+}
+
+func (w *ADDSWarnings) Error() string {
+	return "ADDS warning(s): " + strings.Join(w.Messages, "\n")
+}
+
+// checkADDS converts the <errors> and <warnings> of a data server response according to the client's options
+func (c *client) checkADDS(requestIndex int32, errors []string, warnings []string) error {
+	if c.addsErrors && len(errors) > 0 {
+		return &ADDSError{RequestIndex: requestIndex, Messages: errors}
+	}
+
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	w := &ADDSWarnings{RequestIndex: requestIndex, Messages: warnings}
+	if c.warningHandler != nil {
+		c.warningHandler(w)
+	}
+	if c.addsErrors && c.fatalWarnings {
+		return w
+	}
+
+	return nil
+}
+
+// ValidationError lists every problem Validate found in a set of options
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid options: " + strings.Join(e.Problems, "; ")
+}
+
+// ChunkError is the error of one of the requests a batched request was split into, see WithBatching
+type ChunkError struct {
+	Index    int
+	Stations []string
+	Err      error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("request for %s failed: %v", strings.Join(e.Stations, ","), e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned, together with the merged responses of the other chunks, when some of the requests a batched
+// request was split into failed. ADDS errors and warnings turned into errors by WithADDSErrors fail the chunk they were
+// reported for, its ChunkError wraps the *ADDSError or *ADDSWarnings.
+type BatchError struct {
+	Total  int
+	Failed []*ChunkError
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		messages[i] = f.Error()
+	}
+	return fmt.Sprintf("%d of %d request(s) failed: %s", len(e.Failed), e.Total, strings.Join(messages, "; "))
+}
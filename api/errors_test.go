@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// closeRecorder is a response body that records whether it was closed
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *closeRecorder) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestHTTPError(t *testing.T) {
+	page := "<html>" + strings.Repeat("Service Unavailable ", 100) + "</html>"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, page)
+	}))
+	defer s.Close()
+
+	_, err := NewClient(s.URL).GetMetar(testMetarOptions)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want *HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusServiceUnavailable || httpErr.Status != "503 Service Unavailable" {
+		t.Errorf("status = %d %q, want 503", httpErr.StatusCode, httpErr.Status)
+	}
+	if !strings.HasPrefix(httpErr.URL, s.URL) || !strings.Contains(httpErr.URL, "dataSource=metars") {
+		t.Errorf("URL = %q, want the metars request to %s", httpErr.URL, s.URL)
+	}
+	if httpErr.Body != page[:maxErrorBodyLength] {
+		t.Errorf("Body has %d bytes, want the first %d of the page", len(httpErr.Body), maxErrorBodyLength)
+	}
+}
+
+func TestHTTPErrorClosesBody(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNotFound} {
+		body := &closeRecorder{Reader: strings.NewReader(metarResponse)}
+		transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: body, Request: r}, nil
+		})
+
+		_, err := NewClient("http://example.invalid/", WithTransport(transport)).GetMetar(testMetarOptions)
+		if (err != nil) != (status != http.StatusOK) {
+			t.Errorf("status %d: err = %v", status, err)
+		}
+		if !body.closed {
+			t.Errorf("status %d: response body was not closed", status)
+		}
+	}
+}
+
+func TestCheckADDS(t *testing.T) {
+	const (
+		errorsResponse = `<response><request_index>7</request_index><errors><error>Invalid station string</error></errors>` +
+			`<warnings><warning>Ignoring unknown field</warning></warnings><data num_results="0"></data></response>`
+		warningsResponse = `<response><request_index>8</request_index><errors/>` +
+			`<warnings><warning>Ignoring unknown field</warning><warning>No data</warning></warnings>` +
+			`<data num_results="0"></data></response>`
+	)
+
+	tests := []struct {
+		name         string
+		response     string
+		fatal        *bool // WithADDSErrors(*fatal) if set
+		wantErr      interface{}
+		wantHandled  int // warnings passed to the handler
+		wantMessages []string
+	}{
+		{"errors ignored by default", errorsResponse, nil, nil, 1, nil},
+		{"errors", errorsResponse, boolPtr(false), &ADDSError{}, 0, []string{"Invalid station string"}},
+		{"errors win over fatal warnings", errorsResponse, boolPtr(true), &ADDSError{}, 0, []string{"Invalid station string"}},
+		{"warnings ignored by default", warningsResponse, nil, nil, 2, nil},
+		{"warnings not fatal", warningsResponse, boolPtr(false), nil, 2, nil},
+		{"fatal warnings", warningsResponse, boolPtr(true), &ADDSWarnings{}, 2, []string{"Ignoring unknown field", "No data"}},
+		{"no errors or warnings", metarResponse, boolPtr(true), nil, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.response)
+			}))
+			defer s.Close()
+
+			var handled []string
+			opts := []ClientOption{WithWarningHandler(func(w *ADDSWarnings) { handled = append(handled, w.Messages...) })}
+			if tt.fatal != nil {
+				opts = append(opts, WithADDSErrors(*tt.fatal))
+			}
+
+			r, err := NewClient(s.URL, opts...).GetMetar(testMetarOptions)
+			if r == nil {
+				t.Fatalf("got no response with err = %v, want the decoded response", err)
+			}
+			if len(handled) != tt.wantHandled {
+				t.Errorf("handler got %q, want %d warning(s)", handled, tt.wantHandled)
+			}
+
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("err = %v, want nil", err)
+				}
+			case *ADDSError:
+				var addsErr *ADDSError
+				if !errors.As(err, &addsErr) || addsErr.RequestIndex != 7 || !reflect.DeepEqual(addsErr.Messages, tt.wantMessages) {
+					t.Errorf("err = %#v, want *ADDSError for request 7 with %q", err, tt.wantMessages)
+				}
+			case *ADDSWarnings:
+				var warnings *ADDSWarnings
+				if !errors.As(err, &warnings) || warnings.RequestIndex != 8 || !reflect.DeepEqual(warnings.Messages, tt.wantMessages) {
+					t.Errorf("err = %#v, want *ADDSWarnings for request 8 with %q", err, tt.wantMessages)
+				}
+			default:
+				t.Fatalf("unexpected wantErr %T", want)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,38 @@
+package api
+
+import "strconv"
+
+// floatValue is a type that satisfies the spf13/pflag/Value interface for optional *float64 option fields, which stay
+// nil until the flag is given. This keeps 0, e.g. the equator, apart from "not given".
+type floatValue struct {
+	p **float64
+}
+
+func NewFloatValue(p **float64) *floatValue {
+	return &floatValue{p: p}
+}
+
+func (f *floatValue) String() string {
+	if f.p == nil || *f.p == nil {
+		return ""
+	}
+	return formatFloat(**f.p)
+}
+
+func (f *floatValue) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*f.p = &v
+	return nil
+}
+
+func (f *floatValue) Type() string {
+	return "float64"
+}
+
+// Float returns a pointer to v, for setting optional option fields like QueryOptions.MinLat
+func Float(v float64) *float64 {
+	return &v
+}
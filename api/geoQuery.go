@@ -0,0 +1,222 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RadialQuery selects data within RadiusSM statute miles of a point. It encodes to the data server's
+// "radius;lon,lat" radialDistance syntax.
+type RadialQuery struct {
+	RadiusSM float64
+	Lat      float64
+	Lon      float64
+}
+
+// Waypoint is a point of a flight path, given either as a station identifier or as a latitude/longitude
+type Waypoint struct {
+	StationId string
+	Lat       float64
+	Lon       float64
+}
+
+// FlightPathQuery selects data within MaxDistSM statute miles of a path through Waypoints. It encodes to the data
+// server's "maxDist;waypoint;waypoint;..." flightPath syntax.
+type FlightPathQuery struct {
+	MaxDistSM float64
+	Waypoints []Waypoint
+}
+
+func (r RadialQuery) String() string {
+	return formatFloat(r.RadiusSM) + ";" + formatLonLat(r.Lon, r.Lat)
+}
+
+// ParseRadialQuery parses the "radius;lon,lat" syntax, e.g. "20;-104.5,39.5"
+func ParseRadialQuery(s string) (RadialQuery, error) {
+	parts := strings.Split(s, ";")
+	if len(parts) != 2 {
+		return RadialQuery{}, fmt.Errorf("radial distance %q is not in the form radius;lon,lat", s)
+	}
+
+	radius, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return RadialQuery{}, fmt.Errorf("radial distance %q has an invalid radius", s)
+	}
+
+	lon, lat, err := parseLonLat(parts[1])
+	if err != nil {
+		return RadialQuery{}, fmt.Errorf("radial distance %q: %v", s, err)
+	}
+
+	return RadialQuery{RadiusSM: radius, Lat: lat, Lon: lon}, nil
+}
+
+// validate checks r, which is nil when not given
+func (r *RadialQuery) validate(v *validator) {
+	if r == nil {
+		return
+	}
+	if r.RadiusSM <= 0 {
+		v.addf("radialDistance radius must be positive")
+	}
+	validateLatLon(v, "radialDistance", r.Lat, r.Lon)
+}
+
+func (w Waypoint) String() string {
+	if len(w.StationId) > 0 {
+		return w.StationId
+	}
+	return formatLonLat(w.Lon, w.Lat)
+}
+
+// ParseWaypoint parses a station identifier or a "lon,lat" pair
+func ParseWaypoint(s string) (Waypoint, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, ",") {
+		return Waypoint{StationId: s}, nil
+	}
+
+	lon, lat, err := parseLonLat(s)
+	if err != nil {
+		return Waypoint{}, err
+	}
+	return Waypoint{Lat: lat, Lon: lon}, nil
+}
+
+func (f FlightPathQuery) String() string {
+	parts := []string{formatFloat(f.MaxDistSM)}
+	for _, w := range f.Waypoints {
+		parts = append(parts, w.String())
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseFlightPathQuery parses the "maxDist;waypoint;waypoint;..." syntax, e.g. "57.5;KSEA;-122.5,45.5;KDEN"
+func ParseFlightPathQuery(s string) (FlightPathQuery, error) {
+	parts := strings.Split(s, ";")
+	if len(parts) < 2 {
+		return FlightPathQuery{}, fmt.Errorf("flight path %q is not in the form maxDist;waypoint;waypoint;...", s)
+	}
+
+	maxDist, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return FlightPathQuery{}, fmt.Errorf("flight path %q has an invalid maximum distance", s)
+	}
+
+	f := FlightPathQuery{MaxDistSM: maxDist}
+	for _, part := range parts[1:] {
+		w, err := ParseWaypoint(part)
+		if err != nil {
+			return FlightPathQuery{}, fmt.Errorf("flight path %q: %v", s, err)
+		}
+		f.Waypoints = append(f.Waypoints, w)
+	}
+
+	return f, nil
+}
+
+// validate checks f, which is nil when not given
+func (f *FlightPathQuery) validate(v *validator) {
+	if f == nil {
+		return
+	}
+	if f.MaxDistSM <= 0 {
+		v.addf("flightPath maximum distance must be positive")
+	}
+	if len(f.Waypoints) < 2 {
+		v.addf("flightPath needs at least two waypoints")
+	}
+	for _, w := range f.Waypoints {
+		if len(w.StationId) > 0 {
+			if !stationIdPattern.MatchString(w.StationId) {
+				v.addf("flightPath waypoint %q is not a valid ICAO station identifier", w.StationId)
+			}
+			continue
+		}
+		validateLatLon(v, "flightPath waypoint", w.Lat, w.Lon)
+	}
+}
+
+// radialQueryValue is a type that satisfies the spf13/pflag/Value interface for optional *RadialQuery option fields,
+// which stay nil until the flag is given. This keeps "0;0,0" apart from "not given" so it is validated.
+type radialQueryValue struct {
+	p **RadialQuery
+}
+
+func NewRadialQueryValue(p **RadialQuery) *radialQueryValue {
+	return &radialQueryValue{p: p}
+}
+
+func (r *radialQueryValue) String() string {
+	if r.p == nil || *r.p == nil {
+		return ""
+	}
+	return (*r.p).String()
+}
+
+func (r *radialQueryValue) Set(s string) error {
+	v, err := ParseRadialQuery(s)
+	if err != nil {
+		return err
+	}
+	*r.p = &v
+	return nil
+}
+
+func (r *radialQueryValue) Type() string {
+	return "radius;lon,lat"
+}
+
+// flightPathQueryValue is a type that satisfies the spf13/pflag/Value interface for optional *FlightPathQuery option
+// fields, which stay nil until the flag is given
+type flightPathQueryValue struct {
+	p **FlightPathQuery
+}
+
+func NewFlightPathQueryValue(p **FlightPathQuery) *flightPathQueryValue {
+	return &flightPathQueryValue{p: p}
+}
+
+func (f *flightPathQueryValue) String() string {
+	if f.p == nil || *f.p == nil {
+		return ""
+	}
+	return (*f.p).String()
+}
+
+func (f *flightPathQueryValue) Set(s string) error {
+	v, err := ParseFlightPathQuery(s)
+	if err != nil {
+		return err
+	}
+	*f.p = &v
+	return nil
+}
+
+func (f *flightPathQueryValue) Type() string {
+	return "maxDist;waypoint;..."
+}
+
+func parseLonLat(s string) (lon float64, lat float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%q is not in the form lon,lat", s)
+	}
+
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if lonErr != nil || latErr != nil {
+		return 0, 0, fmt.Errorf("%q is not in the form lon,lat", s)
+	}
+
+	return lon, lat, nil
+}
+
+func formatLonLat(lon, lat float64) string {
+	return formatFloat(lon) + "," + formatFloat(lat)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
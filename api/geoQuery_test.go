@@ -0,0 +1,172 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestParseRadialQuery(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    RadialQuery
+		wantErr bool
+	}{
+		{"20;-104.5,39.5", RadialQuery{RadiusSM: 20, Lat: 39.5, Lon: -104.5}, false},
+		{" 7.5 ; -0.46 , 51.47 ", RadialQuery{RadiusSM: 7.5, Lat: 51.47, Lon: -0.46}, false},
+		{"0;0,0", RadialQuery{}, false},
+		{"20", RadialQuery{}, true},
+		{"20;-104.5,39.5;5", RadialQuery{}, true},
+		{"far;-104.5,39.5", RadialQuery{}, true},
+		{"20;-104.5", RadialQuery{}, true},
+		{"20;west,39.5", RadialQuery{}, true},
+		{"", RadialQuery{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRadialQuery(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRadialQuery(%q) err = %v, want error %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRadialQuery(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseWaypoint(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Waypoint
+		wantErr bool
+	}{
+		{"KSEA", Waypoint{StationId: "KSEA"}, false},
+		{" KDEN ", Waypoint{StationId: "KDEN"}, false},
+		{"-122.5,45.5", Waypoint{Lat: 45.5, Lon: -122.5}, false},
+		{"0,0", Waypoint{}, false},
+		{"-122.5,", Waypoint{}, true},
+		{"-122.5,45.5,100", Waypoint{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseWaypoint(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWaypoint(%q) err = %v, want error %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseWaypoint(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseFlightPathQuery(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    FlightPathQuery
+		wantErr bool
+	}{
+		{
+			"57.5;KSEA;-122.5,45.5;KDEN",
+			FlightPathQuery{MaxDistSM: 57.5, Waypoints: []Waypoint{{StationId: "KSEA"}, {Lat: 45.5, Lon: -122.5}, {StationId: "KDEN"}}},
+			false,
+		},
+		{"50;KSEA", FlightPathQuery{MaxDistSM: 50, Waypoints: []Waypoint{{StationId: "KSEA"}}}, false},
+		{"50", FlightPathQuery{}, true},
+		{"far;KSEA;KDEN", FlightPathQuery{}, true},
+		{"50;KSEA;-122.5", FlightPathQuery{MaxDistSM: 50, Waypoints: []Waypoint{{StationId: "KSEA"}, {StationId: "-122.5"}}}, false},
+		{"50;KSEA;-122.5,north", FlightPathQuery{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFlightPathQuery(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFlightPathQuery(%q) err = %v, want error %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFlightPathQuery(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestGeoQueryString(t *testing.T) {
+	for _, s := range []string{"20;-104.5,39.5", "0;0,0"} {
+		r, err := ParseRadialQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.String(); got != s {
+			t.Errorf("ParseRadialQuery(%q).String() = %q", s, got)
+		}
+	}
+
+	for _, s := range []string{"57.5;KSEA;-122.5,45.5;KDEN", "0;0,0;KDEN"} {
+		f, err := ParseFlightPathQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.String(); got != s {
+			t.Errorf("ParseFlightPathQuery(%q).String() = %q", s, got)
+		}
+	}
+}
+
+func TestGeoQueryFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantQuery map[string]string
+		wantErr   bool // from Validate
+	}{
+		{"not given", nil, map[string]string{}, false},
+		{"radial distance", []string{"--radialDistance", "20;-104.5,39.5"}, map[string]string{"radialDistance": "20;-104.5,39.5"}, false},
+		{"zero radial distance", []string{"--radialDistance", "0;0,0"}, map[string]string{"radialDistance": "0;0,0"}, true},
+		{"flight path", []string{"--flightPath", "50;KSEA;KDEN"}, map[string]string{"flightPath": "50;KSEA;KDEN"}, false},
+		{"zero flight path distance", []string{"--flightPath", "0;KSEA;KDEN"}, map[string]string{"flightPath": "0;KSEA;KDEN"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o MetarOptions
+			flags := pflag.NewFlagSet("metar", pflag.ContinueOnError)
+			flags.Var(NewRadialQueryValue(&o.RadialDistance), "radialDistance", "")
+			flags.Var(NewFlightPathQueryValue(&o.FlightPath), "flightPath", "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+
+			got := map[string]string{}
+			for _, key := range []string{"radialDistance", "flightPath"} {
+				if values := o.Values(); values.Get(key) != "" {
+					got[key] = values.Get(key)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantQuery) {
+				t.Errorf("query = %v, want %v", got, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestGeoQueryFlagsReject(t *testing.T) {
+	var o MetarOptions
+	flags := pflag.NewFlagSet("metar", pflag.ContinueOnError)
+	flags.Var(NewRadialQueryValue(&o.RadialDistance), "radialDistance", "")
+	flags.Var(NewFlightPathQueryValue(&o.FlightPath), "flightPath", "")
+
+	for _, args := range [][]string{{"--radialDistance", "20"}, {"--flightPath", "50"}} {
+		if err := flags.Parse(args); err == nil {
+			t.Errorf("parsing %q succeeded, want an error", args)
+		}
+	}
+	if o.RadialDistance != nil || o.FlightPath != nil {
+		t.Errorf("options = %+v, %+v after invalid flags, want both nil", o.RadialDistance, o.FlightPath)
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// query is implemented by the options of every data source. It is the single path through which options become
+// data server request parameters.
+type query interface {
+	// DataSource returns the value of the dataSource request parameter, e.g. "metars"
+	DataSource() string
+	// Values returns the request parameters selecting the data, without dataSource, requestType and format
+	Values() url.Values
+}
+
+// QueryOptions holds the constraints shared by all data sources
+type QueryOptions struct {
+	StartTime      timeValue
+	EndTime        timeValue
+	HoursBeforeNow int32
+	MostRecent     bool
+	MinLat         *float64 // bounding box, nil when not given
+	MaxLat         *float64
+	MinLon         *float64
+	MaxLon         *float64
+	RadialDistance *RadialQuery // nil when not given
+	FlightPath     *FlightPathQuery
+	Fields         []string
+}
+
+// StationOptions holds the constraints of data sources that report by station
+type StationOptions struct {
+	Stations                 []string
+	MostRecentForEachStation bool
+	MinDegreeDistance        float64
+}
+
+type MetarOptions struct {
+	QueryOptions
+	StationOptions
+}
+
+type TafOptions struct {
+	QueryOptions
+	StationOptions
+	TimeType string
+}
+
+type AircraftReportOptions struct {
+	QueryOptions
+	MinAltitudeFt int32
+	MaxAltitudeFt int32
+}
+
+// AirSigmetOptions selects AIRMETs and SIGMETs. The data server only constrains them by time, use
+// airsigmets.Response.Filter to select them by area or hazard.
+type AirSigmetOptions struct {
+	StartTime      timeValue
+	EndTime        timeValue
+	HoursBeforeNow int32
+	Fields         []string
+}
+
+// GAirmetOptions selects G-AIRMETs. The data server only constrains them by time, use gairmets.Response.Filter to
+// select them by product, hazard or area.
+type GAirmetOptions struct {
+	StartTime      timeValue
+	EndTime        timeValue
+	HoursBeforeNow int32
+	Fields         []string
+}
+
+// StationInfoOptions selects station information. Stations are not reported over time so there are no time
+// constraints.
+type StationInfoOptions struct {
+	Stations       []string
+	MinLat         *float64 // bounding box, nil when not given
+	MaxLat         *float64
+	MinLon         *float64
+	MaxLon         *float64
+	RadialDistance *RadialQuery // nil when not given
+	FlightPath     *FlightPathQuery
+	Fields         []string
+}
+
+func (o QueryOptions) encode(q url.Values) {
+	encodeTimeRange(q, o.StartTime, o.EndTime, o.HoursBeforeNow)
+	setBool(q, "mostRecent", o.MostRecent)
+	encodeArea(q, o.MinLat, o.MaxLat, o.MinLon, o.MaxLon, o.RadialDistance, o.FlightPath)
+	setList(q, "fields", o.Fields)
+}
+
+func encodeTimeRange(q url.Values, startTime, endTime timeValue, hoursBeforeNow int32) {
+	setTime(q, "startTime", startTime)
+	setTime(q, "endTime", endTime)
+	setInt(q, "hoursBeforeNow", hoursBeforeNow)
+}
+
+func encodeArea(q url.Values, minLat, maxLat, minLon, maxLon *float64, radialDistance *RadialQuery, flightPath *FlightPathQuery) {
+	setOptionalFloat(q, "minLat", minLat)
+	setOptionalFloat(q, "maxLat", maxLat)
+	setOptionalFloat(q, "minLon", minLon)
+	setOptionalFloat(q, "maxLon", maxLon)
+	if radialDistance != nil {
+		q.Set("radialDistance", radialDistance.String())
+	}
+	if flightPath != nil {
+		q.Set("flightPath", flightPath.String())
+	}
+}
+
+func (o StationOptions) encode(q url.Values) {
+	setList(q, "stationString", o.Stations)
+	setBool(q, "mostRecentForEachStation", o.MostRecentForEachStation)
+	setFloat(q, "minDegreeDistance", o.MinDegreeDistance)
+}
+
+func (o MetarOptions) DataSource() string {
+	return "metars"
+}
+
+func (o MetarOptions) Values() url.Values {
+	q := url.Values{}
+	o.QueryOptions.encode(q)
+	o.StationOptions.encode(q)
+	return q
+}
+
+func (o TafOptions) DataSource() string {
+	return "tafs"
+}
+
+func (o TafOptions) Values() url.Values {
+	q := url.Values{}
+	o.QueryOptions.encode(q)
+	o.StationOptions.encode(q)
+	setString(q, "timeType", o.TimeType)
+	return q
+}
+
+func (o AircraftReportOptions) DataSource() string {
+	return "aircraftreports"
+}
+
+func (o AircraftReportOptions) Values() url.Values {
+	q := url.Values{}
+	o.QueryOptions.encode(q)
+	setInt(q, "minAltitudeFt", o.MinAltitudeFt)
+	setInt(q, "maxAltitudeFt", o.MaxAltitudeFt)
+	return q
+}
+
+func (o AirSigmetOptions) DataSource() string {
+	return "airsigmets"
+}
+
+func (o AirSigmetOptions) Values() url.Values {
+	q := url.Values{}
+	encodeTimeRange(q, o.StartTime, o.EndTime, o.HoursBeforeNow)
+	setList(q, "fields", o.Fields)
+	return q
+}
+
+func (o GAirmetOptions) DataSource() string {
+	return "gairmets"
+}
+
+func (o GAirmetOptions) Values() url.Values {
+	q := url.Values{}
+	encodeTimeRange(q, o.StartTime, o.EndTime, o.HoursBeforeNow)
+	setList(q, "fields", o.Fields)
+	return q
+}
+
+func (o StationInfoOptions) DataSource() string {
+	return "stations"
+}
+
+func (o StationInfoOptions) Values() url.Values {
+	q := url.Values{}
+	setList(q, "stationString", o.Stations)
+	encodeArea(q, o.MinLat, o.MaxLat, o.MinLon, o.MaxLon, o.RadialDistance, o.FlightPath)
+	setList(q, "fields", o.Fields)
+	return q
+}
+
+// the set* helpers below add a parameter only when its value is not the zero value
+
+func setString(q url.Values, key string, value string) {
+	if len(value) > 0 {
+		q.Set(key, value)
+	}
+}
+
+func setList(q url.Values, key string, values []string) {
+	if len(values) > 0 {
+		q.Set(key, strings.Join(values, " "))
+	}
+}
+
+func setTime(q url.Values, key string, value timeValue) {
+	if !time.Time(value).IsZero() {
+		q.Set(key, value.String())
+	}
+}
+
+func setInt(q url.Values, key string, value int32) {
+	if value > 0 {
+		q.Set(key, strconv.FormatInt(int64(value), 10))
+	}
+}
+
+func setFloat(q url.Values, key string, value float64) {
+	if value != 0 {
+		q.Set(key, formatFloat(value))
+	}
+}
+
+// setOptionalFloat adds a parameter whenever value is given, including 0
+func setOptionalFloat(q url.Values, key string, value *float64) {
+	if value != nil {
+		q.Set(key, formatFloat(*value))
+	}
+}
+
+func setBool(q url.Values, key string, value bool) {
+	if value {
+		q.Set(key, strconv.FormatBool(value))
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func testTime(s string) timeValue {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return timeValue(t)
+}
+
+func TestDataSource(t *testing.T) {
+	tests := []struct {
+		q    query
+		want string
+	}{
+		{MetarOptions{}, "metars"},
+		{TafOptions{}, "tafs"},
+		{AircraftReportOptions{}, "aircraftreports"},
+		{AirSigmetOptions{}, "airsigmets"},
+		{GAirmetOptions{}, "gairmets"},
+		{StationInfoOptions{}, "stations"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.DataSource(); got != tt.want {
+			t.Errorf("%T.DataSource() = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	timeRange := QueryOptions{StartTime: testTime("2026-10-18T06:00:00Z"), EndTime: testTime("2026-10-18T12:30:00Z")}
+	area := QueryOptions{MinLat: Float(41.5), MaxLat: Float(42.25), MinLon: Float(-88), MaxLon: Float(-87.5)}
+
+	tests := []struct {
+		name string
+		q    query
+		want string // url.Values.Encode() of the expected values
+	}{
+		{"zero metar", MetarOptions{}, ""},
+		{"zero taf", TafOptions{}, ""},
+		{"zero aircraft reports", AircraftReportOptions{}, ""},
+		{"zero airsigmets", AirSigmetOptions{}, ""},
+		{"zero gairmets", GAirmetOptions{}, ""},
+		{"zero stations", StationInfoOptions{}, ""},
+		{
+			"metar stations",
+			MetarOptions{
+				QueryOptions:   QueryOptions{HoursBeforeNow: 3, MostRecent: true},
+				StationOptions: StationOptions{Stations: []string{"KORD", "KMDW", "@WI"}, MostRecentForEachStation: true},
+			},
+			"hoursBeforeNow=3&mostRecent=true&mostRecentForEachStation=true&stationString=KORD+KMDW+%40WI",
+		},
+		{
+			"metar time range",
+			MetarOptions{QueryOptions: timeRange},
+			"endTime=2026-10-18T12%3A30%3A00Z&startTime=2026-10-18T06%3A00%3A00Z",
+		},
+		{
+			"metar area and fields",
+			MetarOptions{
+				QueryOptions:   QueryOptions{MinLat: area.MinLat, MaxLat: area.MaxLat, MinLon: area.MinLon, MaxLon: area.MaxLon, Fields: []string{"raw_text", "station_id"}},
+				StationOptions: StationOptions{MinDegreeDistance: 0.5},
+			},
+			"fields=raw_text+station_id&maxLat=42.25&maxLon=-87.5&minDegreeDistance=0.5&minLat=41.5&minLon=-88",
+		},
+		{
+			"bounds of 0 are kept",
+			StationInfoOptions{MinLat: Float(0), MaxLat: Float(10), MinLon: Float(-10), MaxLon: Float(0)},
+			"maxLat=10&maxLon=0&minLat=0&minLon=-10",
+		},
+		{
+			"metar radial distance",
+			MetarOptions{QueryOptions: QueryOptions{RadialDistance: &RadialQuery{RadiusSM: 20, Lat: 39.5, Lon: -104.5}}},
+			"radialDistance=20%3B-104.5%2C39.5",
+		},
+		{
+			"metar flight path",
+			MetarOptions{QueryOptions: QueryOptions{FlightPath: &FlightPathQuery{MaxDistSM: 57.5, Waypoints: []Waypoint{{StationId: "KSEA"}, {Lat: 45.5, Lon: -122.5}, {StationId: "KDEN"}}}}},
+			"flightPath=57.5%3BKSEA%3B-122.5%2C45.5%3BKDEN",
+		},
+		{
+			"taf time type",
+			TafOptions{QueryOptions: timeRange, StationOptions: StationOptions{Stations: []string{"KORD"}}, TimeType: "issue"},
+			"endTime=2026-10-18T12%3A30%3A00Z&startTime=2026-10-18T06%3A00%3A00Z&stationString=KORD&timeType=issue",
+		},
+		{
+			"aircraft reports altitudes",
+			AircraftReportOptions{QueryOptions: QueryOptions{HoursBeforeNow: 1}, MinAltitudeFt: 5000, MaxAltitudeFt: 25000},
+			"hoursBeforeNow=1&maxAltitudeFt=25000&minAltitudeFt=5000",
+		},
+		{
+			"airsigmets",
+			AirSigmetOptions{StartTime: timeRange.StartTime, EndTime: timeRange.EndTime, Fields: []string{"raw_text"}},
+			"endTime=2026-10-18T12%3A30%3A00Z&fields=raw_text&startTime=2026-10-18T06%3A00%3A00Z",
+		},
+		{
+			"gairmets",
+			GAirmetOptions{HoursBeforeNow: 2, Fields: []string{"product", "hazard"}},
+			"fields=product+hazard&hoursBeforeNow=2",
+		},
+		{
+			"stations",
+			StationInfoOptions{Stations: []string{"KORD", "KMDW"}, RadialDistance: &RadialQuery{RadiusSM: 10, Lat: 41.9, Lon: -87.9}},
+			"radialDistance=10%3B-87.9%2C41.9&stationString=KORD+KMDW",
+		},
+		{
+			"negative and zero counts are left out",
+			AircraftReportOptions{QueryOptions: QueryOptions{HoursBeforeNow: -1}, MinAltitudeFt: 0},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.Values().Encode(); got != tt.want {
+				t.Errorf("Values() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetrieveURL(t *testing.T) {
+	c := NewClient("https://example.com/httpparam").(*client)
+
+	u, err := c.retrieveURL(MetarOptions{StationOptions: StationOptions{Stations: []string{"KORD"}}}, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "dataSource=metars&format=csv&requestType=retrieve&stationString=KORD"
+	if got := parsed.RawQuery; got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+
+	c = NewClient(DefaultDataApiEndPoint, WithBackend(BackendDataAPI)).(*client)
+	if _, err := c.retrieveURL(MetarOptions{}, FormatXML); err == nil {
+		t.Error("retrieveURL with the Data API backend succeeded, want an error")
+	}
+}
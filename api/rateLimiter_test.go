@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func (l *RateLimiter) available() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.tokens
+}
+
+func TestRateLimiterConcurrentWait(t *testing.T) {
+	const (
+		rate    = 100
+		burst   = 5
+		callers = 25
+	)
+	l := NewRateLimiter(rate, burst)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	waited := make([]time.Duration, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := l.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+			waited[i] = time.Since(start)
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	// the burst passes at once, the other callers get one token every 10ms
+	want := time.Duration(callers-burst) * time.Second / rate
+	if elapsed < want-10*time.Millisecond || elapsed > want+2*time.Second {
+		t.Errorf("%d callers took %v, want about %v", callers, elapsed, want)
+	}
+
+	// the k-th caller past the burst cannot have passed before k tokens were added
+	sort.Slice(waited, func(i, j int) bool { return waited[i] < waited[j] })
+	for k := burst; k < callers; k++ {
+		if earliest := time.Duration(k+1-burst)*time.Second/rate - 2*time.Millisecond; waited[k] < earliest {
+			t.Errorf("caller %d passed after %v, want not before %v", k+1, waited[k], earliest)
+		}
+	}
+
+	// every caller took exactly one token, and the reservations were made well within the 10ms that refill one
+	if got := l.available(); got < burst-callers || got >= burst-callers+1 {
+		t.Errorf("tokens = %v, want %d plus less than one refilled", got, burst-callers)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := l.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("returned after %v, want soon after the deadline", elapsed)
+	}
+
+	// the canceled caller gave its token back, so the bucket is refilling from 0 instead of -1
+	if got := l.available(); got < 0 || got > 0.5 {
+		t.Errorf("tokens = %v, want the canceled reservation returned", got)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	var nilLimiter *RateLimiter
+	for _, l := range []*RateLimiter{nilLimiter, NewRateLimiter(0, 1)} {
+		for i := 0; i < 100; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRateLimiterSharedByClients(t *testing.T) {
+	s, requests := failingServer(t, 0, 0, nil)
+	l := NewRateLimiter(50, 1)
+	clients := []Client{NewClient(s.URL, WithRateLimiter(l)), NewClient(s.URL, WithRateLimiter(l))}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(c Client) {
+			defer wg.Done()
+			if _, err := c.GetMetar(testMetarOptions); err != nil {
+				t.Error(err)
+			}
+		}(clients[i%2])
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 requests at 50/s with a burst of 1 took %v, want at least 100ms", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 6 {
+		t.Errorf("requests = %d, want 6", got)
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. The zero value disables retries.
+type RetryPolicy struct {
+	MaxAttempts int           // total number of attempts, including the first one
+	BaseDelay   time.Duration // delay before the second attempt, doubled for each further attempt
+	MaxDelay    time.Duration // upper bound for the delay between attempts, 0 means no bound
+	Jitter      float64       // fraction (0..1) of each delay that is randomized
+	Retryable   func(statusCode int) bool
+}
+
+// DefaultRetryPolicy retries transient server errors and dropped connections up to 4 times in total
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    8 * time.Second,
+	Jitter:      0.2,
+	Retryable:   IsRetryableStatus,
+}
+
+// IsRetryableStatus reports whether an HTTP status code indicates a transient server condition
+func IsRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryError is returned when a request still fails after more than one attempt
+type RetryError struct {
+	Attempts int
+	Err      error // error of the last attempt
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// shouldRetry reports whether err, returned by an attempt made with ctx, may go away on another attempt
+func (p *RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		retryable := p.Retryable
+		if retryable == nil {
+			retryable = IsRetryableStatus
+		}
+		return retryable(httpErr.StatusCode)
+	}
+
+	// anything else that is not an HTTP status problem failed in transport, e.g. a dropped connection
+	return true
+}
+
+// delay returns the wait time before the given attempt (2 for the first retry)
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 2; i < attempt; i++ {
+		d *= 2
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 && d > 0 {
+		j := time.Duration(math.Min(p.Jitter, 1) * float64(d))
+		d = d - j + time.Duration(rand.Int63n(int64(2*j)+1))
+	}
+	// jitter spreads delays below the cap but must not push them over it
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	return d
+}
+
+// retryDelay returns the wait time before the given attempt, which is longer than delay's if the server asked for it
+// with a Retry-After header, but never longer than MaxDelay
+func (p *RetryPolicy) retryDelay(attempt int, err error) time.Duration {
+	d := p.delay(attempt)
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > d {
+		d = httpErr.RetryAfter
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+	}
+
+	return d
+}
+
+// sleep waits for d or until ctx is done, whichever comes first
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const metarResponse = `<response><request_index>1</request_index><data num_results="1">` +
+	`<METAR><raw_text>KORD 181151Z 27015KT 10SM CLR 12/M03 A3012</raw_text><station_id>KORD</station_id></METAR>` +
+	`</data></response>`
+
+// failingServer answers the first failures requests with status and all further requests with a METAR response
+func failingServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, metarResponse)
+	}))
+	t.Cleanup(s.Close)
+
+	return s, &requests
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+	Retryable:   IsRetryableStatus,
+}
+
+var testMetarOptions = MetarOptions{StationOptions: StationOptions{Stations: []string{"KORD"}}}
+
+func TestRetryAttempts(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		wantRequests int32
+		wantAttempts int // Attempts of the returned *RetryError, 0 if none is expected
+		wantErr      bool
+	}{
+		{"no failures", 0, http.StatusServiceUnavailable, 1, 0, false},
+		{"recovers after failures", 3, http.StatusServiceUnavailable, 4, 0, false},
+		{"gives up after max attempts", 10, http.StatusBadGateway, 4, 4, true},
+		{"too many requests is retried", 2, http.StatusTooManyRequests, 3, 0, false},
+		{"bad request is not retried", 10, http.StatusBadRequest, 1, 0, true},
+		{"not found is not retried", 10, http.StatusNotFound, 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, requests := failingServer(t, tt.failures, tt.status, nil)
+			c := NewClient(s.URL, WithRetryPolicy(testRetryPolicy))
+
+			r, err := c.GetMetar(testMetarOptions)
+			if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(r.Data.Metars) != 1 {
+				t.Errorf("got %d METARs, want 1", len(r.Data.Metars))
+			}
+
+			var retryErr *RetryError
+			if isRetryErr := errors.As(err, &retryErr); isRetryErr != (tt.wantAttempts > 0) {
+				t.Fatalf("err = %v, want *RetryError %v", err, tt.wantAttempts > 0)
+			}
+			if retryErr != nil && retryErr.Attempts != tt.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", retryErr.Attempts, tt.wantAttempts)
+			}
+
+			var httpErr *HTTPError
+			if tt.wantErr && (!errors.As(err, &httpErr) || httpErr.StatusCode != tt.status) {
+				t.Errorf("err = %v, want *HTTPError with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestRetryWithoutPolicy(t *testing.T) {
+	s, requests := failingServer(t, 1, http.StatusServiceUnavailable, nil)
+	c := NewClient(s.URL)
+
+	_, err := c.GetMetar(testMetarOptions)
+	var retryErr *RetryError
+	if err == nil || errors.As(err, &retryErr) {
+		t.Errorf("err = %v, want a plain *HTTPError", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	s, requests := failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	policy := testRetryPolicy
+	policy.MaxDelay = 0
+	c := NewClient(s.URL, WithRetryPolicy(policy))
+
+	start := time.Now()
+	if _, err := c.GetMetar(testMetarOptions); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s asked for by Retry-After", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestRetryAfterIsCappedByMaxDelay(t *testing.T) {
+	s, _ := failingServer(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"3600"}})
+	c := NewClient(s.URL, WithRetryPolicy(testRetryPolicy))
+
+	start := time.Now()
+	if _, err := c.GetMetar(testMetarOptions); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retried after %v, want at most about MaxDelay", elapsed)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"120", 2 * time.Minute},
+		{"-5", 0},
+		{"Sun, 18 Oct 2026 12:00:30 GMT", 30 * time.Second},
+		{"Sun, 18 Oct 2026 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.value, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryContextCancelledDuringBackoff(t *testing.T) {
+	s, requests := failingServer(t, 10, http.StatusServiceUnavailable, nil)
+	policy := testRetryPolicy
+	policy.BaseDelay, policy.MaxDelay = time.Hour, time.Hour
+	c := NewClient(s.URL, WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetMetarContext(ctx, testMetarOptions)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want soon after the context is done", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 1 {
+		t.Errorf("err = %v, want *RetryError after 1 attempt", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := p.delay(i + 2); got != w {
+			t.Errorf("delay(%d) = %v, want %v", i+2, got, w)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.delay(2); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("delay(2) with jitter = %v, want within 50ms..150ms", got)
+		}
+	}
+
+	capped := false
+	for i := 0; i < 100; i++ {
+		got := p.delay(6)
+		if got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("delay(6) with jitter = %v, want within 500ms..MaxDelay", got)
+		}
+		capped = capped || got == time.Second
+	}
+	if !capped {
+		t.Error("delay(6) with jitter never reached MaxDelay, want jitter above the cap clamped to it")
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/tafs"
+)
+
+// StreamMetars calls fn for each METAR selected by options while the response is being decoded, so memory use does
+// not grow with the size of the response. Streaming stops at the first error returned by fn, which is returned.
+// The data API backend and the CSV format do not support streaming, for them the whole response is decoded first.
+// Batched requests are streamed one chunk after the other.
+func (c *client) StreamMetars(ctx context.Context, options MetarOptions, fn func(metars.Metar) error) error {
+	if c.backend != BackendADDS || c.format == FormatCSV {
+		r, err := c.GetMetarContext(ctx, options)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.Data.Metars {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decode := func(d *xml.Decoder, start *xml.StartElement) error {
+		var m metars.Metar
+		if err := d.DecodeElement(&m, start); err != nil {
+			return err
+		}
+		return fn(m)
+	}
+
+	if !c.batching(options.Stations) {
+		return c.streamElements(ctx, options, "METAR", decode)
+	}
+	for _, chunk := range c.chunks(options.Stations) {
+		o := options
+		o.Stations = chunk
+		if err := c.streamElements(ctx, o, "METAR", decode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamTafs calls fn for each TAF selected by options while the response is being decoded, see StreamMetars
+func (c *client) StreamTafs(ctx context.Context, options TafOptions, fn func(tafs.Taf) error) error {
+	if c.backend != BackendADDS || c.format == FormatCSV {
+		r, err := c.GetTafContext(ctx, options)
+		if err != nil {
+			return err
+		}
+		for _, t := range r.Data.Tafs {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decode := func(d *xml.Decoder, start *xml.StartElement) error {
+		var t tafs.Taf
+		if err := d.DecodeElement(&t, start); err != nil {
+			return err
+		}
+		return fn(t)
+	}
+
+	if !c.batching(options.Stations) {
+		return c.streamElements(ctx, options, "TAF", decode)
+	}
+	for _, chunk := range c.chunks(options.Stations) {
+		o := options
+		o.Stations = chunk
+		if err := c.streamElements(ctx, o, "TAF", decode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamElements requests the data selected by q and calls decode for every element named element in the response.
+// The response's errors and warnings are checked the same way as for non-streamed requests once the response is
+// complete.
+func (c *client) streamElements(ctx context.Context, q query, element string, decode func(*xml.Decoder, *xml.StartElement) error) error {
+	u, err := c.retrieveURL(q, FormatXML)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.stream(ctx, q.DataSource(), u)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var requestIndex int32
+	var errors, warnings []string
+
+	d := xml.NewDecoder(body)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("reading response aborted: %w", ctxErr)
+			}
+			return err
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case element:
+			err = decode(d, &start)
+		case "request_index":
+			err = d.DecodeElement(&requestIndex, &start)
+		case "error":
+			var s string
+			err = d.DecodeElement(&s, &start)
+			errors = append(errors, s)
+		case "warning":
+			var s string
+			err = d.DecodeElement(&s, &start)
+			warnings = append(warnings, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.checkADDS(requestIndex, errors, warnings)
+}
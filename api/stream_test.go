@@ -0,0 +1,302 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/theperiscope/avwx/metars"
+	"github.com/theperiscope/avwx/tafs"
+)
+
+// eventLog records requests and streamed reports in the order they happen
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(format string, a ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, fmt.Sprintf(format, a...))
+}
+
+func (l *eventLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.events...)
+}
+
+// streamServer answers METAR and TAF requests with one report per requested station, followed by the given errors
+// and warnings, which the data server lists before the data but may come in any order. It logs every request.
+func streamServer(t *testing.T, log *eventLog, errs, warnings []string) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		stations := strings.Fields(q.Get("stationString"))
+		log.add("request %s", strings.Join(stations, " "))
+
+		element := "METAR"
+		if q.Get("dataSource") == "tafs" {
+			element = "TAF"
+		}
+
+		fmt.Fprintf(w, `<response version="1.2"><request_index>42</request_index><data num_results="%d">`, len(stations))
+		for _, station := range stations {
+			fmt.Fprintf(w, "<%s><raw_text>%s 181151Z</raw_text><station_id>%s</station_id></%s>", element, station, station, element)
+		}
+		fmt.Fprint(w, "</data><errors>")
+		for _, e := range errs {
+			fmt.Fprintf(w, "<error>%s</error>", e)
+		}
+		fmt.Fprint(w, "</errors><warnings>")
+		for _, warning := range warnings {
+			fmt.Fprintf(w, "<warning>%s</warning>", warning)
+		}
+		fmt.Fprint(w, "</warnings></response>")
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestStreamMetars(t *testing.T) {
+	log := &eventLog{}
+	c := NewClient(streamServer(t, log, nil, nil).URL)
+
+	err := c.StreamMetars(context.Background(), batchOptions("KORD", "KMDW", "KSFO"), func(m metars.Metar) error {
+		log.add("metar %s", m.StationId)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"request KORD KMDW KSFO", "metar KORD", "metar KMDW", "metar KSFO"}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestStreamTafs(t *testing.T) {
+	log := &eventLog{}
+	c := NewClient(streamServer(t, log, nil, nil).URL)
+
+	err := c.StreamTafs(context.Background(), TafOptions{StationOptions: StationOptions{Stations: []string{"KDEN", "KSEA"}}}, func(taf tafs.Taf) error {
+		log.add("taf %s", taf.StationId)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"request KDEN KSEA", "taf KDEN", "taf KSEA"}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestStreamStopsAtCallbackError(t *testing.T) {
+	stop := errors.New("enough")
+	log := &eventLog{}
+	c := NewClient(streamServer(t, log, nil, nil).URL, WithBatching(2, 1))
+
+	calls := 0
+	err := c.StreamMetars(context.Background(), batchOptions("KORD", "KMDW", "KSFO", "KLAX"), func(m metars.Metar) error {
+		calls++
+		if m.StationId == "KMDW" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("err = %v, want the callback's error itself", err)
+	}
+	if calls != 2 {
+		t.Errorf("callback called %d times, want 2", calls)
+	}
+	if got := log.get(); !reflect.DeepEqual(got, []string{"request KORD KMDW"}) {
+		t.Errorf("requests = %q, want the second chunk never requested", got)
+	}
+}
+
+func TestStreamChecksADDSAfterBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		errs        []string
+		warnings    []string
+		opts        []ClientOption
+		wantErr     interface{}
+		wantHandled []string
+	}{
+		{"errors", []string{"Invalid station string: KXYZ"}, nil, []ClientOption{WithADDSErrors(false)}, &ADDSError{}, nil},
+		{"errors ignored by default", []string{"Invalid station string: KXYZ"}, nil, nil, nil, nil},
+		{"fatal warnings", nil, []string{"Station list shortened"}, []ClientOption{WithADDSErrors(true)}, &ADDSWarnings{}, []string{"Station list shortened"}},
+		{"warnings", nil, []string{"Station list shortened"}, nil, nil, []string{"Station list shortened"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled []string
+			opts := append([]ClientOption{WithWarningHandler(func(w *ADDSWarnings) {
+				handled = append(handled, w.Messages...)
+			})}, tt.opts...)
+			c := NewClient(streamServer(t, &eventLog{}, tt.errs, tt.warnings).URL, opts...)
+
+			var streamed []string
+			err := c.StreamMetars(context.Background(), batchOptions("KORD", "KMDW"), func(m metars.Metar) error {
+				streamed = append(streamed, m.StationId)
+				return nil
+			})
+
+			// the reports arrive before the errors and warnings are known
+			if !reflect.DeepEqual(streamed, []string{"KORD", "KMDW"}) {
+				t.Errorf("streamed %q, want both METARs", streamed)
+			}
+			if !reflect.DeepEqual(handled, tt.wantHandled) {
+				t.Errorf("handler got %q, want %q", handled, tt.wantHandled)
+			}
+
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("err = %v, want nil", err)
+				}
+			case *ADDSError:
+				var addsErr *ADDSError
+				if !errors.As(err, &addsErr) || addsErr.RequestIndex != 42 || !reflect.DeepEqual(addsErr.Messages, tt.errs) {
+					t.Errorf("err = %#v, want *ADDSError for request 42 with %q", err, tt.errs)
+				}
+			case *ADDSWarnings:
+				var warnings *ADDSWarnings
+				if !errors.As(err, &warnings) || !reflect.DeepEqual(warnings.Messages, tt.warnings) {
+					t.Errorf("err = %#v, want *ADDSWarnings with %q", err, tt.warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamCanceledMidBody(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<response><request_index>1</request_index><data num_results="2">`+
+			`<METAR><raw_text>KORD 181151Z</raw_text><station_id>KORD</station_id></METAR>`)
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	var streamed []string
+	err := NewClient(s.URL).StreamMetars(ctx, testMetarOptions, func(m metars.Metar) error {
+		streamed = append(streamed, m.StationId)
+		cancel()
+		return nil
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want soon after the cancellation", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) || !strings.HasPrefix(err.Error(), "reading response aborted") {
+		t.Errorf("err = %v, want reading response aborted wrapping context.Canceled", err)
+	}
+	if !reflect.DeepEqual(streamed, []string{"KORD"}) {
+		t.Errorf("streamed %q, want the METAR that arrived before the cancellation", streamed)
+	}
+}
+
+func TestStreamBatchedChunkByChunk(t *testing.T) {
+	log := &eventLog{}
+	c := NewClient(streamServer(t, log, nil, nil).URL, WithBatching(2, 4))
+
+	err := c.StreamMetars(context.Background(), batchOptions("KAAA", "KBBB", "KCCC", "KDDD", "KAAA", "KEEE"), func(m metars.Metar) error {
+		log.add("metar %s", m.StationId)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each chunk is requested only after the previous one was streamed, whatever the number of workers
+	want := []string{
+		"request KAAA KBBB", "metar KAAA", "metar KBBB",
+		"request KCCC KDDD", "metar KCCC", "metar KDDD",
+		"request KEEE", "metar KEEE",
+	}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestStreamFallsBackToFullDecoding(t *testing.T) {
+	csvServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("format"); got != "csv" {
+			t.Errorf("format = %q, want csv", got)
+		}
+		name := "adds_metars.csv"
+		if r.URL.Query().Get("dataSource") == "tafs" {
+			name = "adds_tafs.csv"
+		}
+		w.Write(readTestdata(t, name))
+	}))
+	defer csvServer.Close()
+	dataApi, _ := dataApiServer(t)
+
+	tests := []struct {
+		name       string
+		url        string
+		opts       []ClientOption
+		wantMetars []string
+		wantTafs   []string
+	}{
+		{"csv", csvServer.URL, []ClientOption{WithFormat(FormatCSV)}, []string{"KORD", "KSFO"}, []string{"KDEN", "KSEA"}},
+		{"data api", dataApi.URL, []ClientOption{WithBackend(BackendDataAPI)}, []string{"KORD", "KSFO", "KXYZ"}, []string{"KDEN"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(tt.url, tt.opts...)
+
+			var gotMetars, gotTafs []string
+			if err := c.StreamMetars(context.Background(), batchOptions("KORD", "KSFO", "KXYZ"), func(m metars.Metar) error {
+				gotMetars = append(gotMetars, m.StationId)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.StreamTafs(context.Background(), TafOptions{StationOptions: StationOptions{Stations: []string{"KDEN", "KSEA"}}}, func(taf tafs.Taf) error {
+				gotTafs = append(gotTafs, taf.StationId)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotMetars, tt.wantMetars) || !reflect.DeepEqual(gotTafs, tt.wantTafs) {
+				t.Errorf("streamed METARs %q and TAFs %q, want %q and %q", gotMetars, gotTafs, tt.wantMetars, tt.wantTafs)
+			}
+
+			stop := errors.New("stop")
+			calls := 0
+			err := c.StreamMetars(context.Background(), batchOptions("KORD", "KSFO", "KXYZ"), func(metars.Metar) error {
+				calls++
+				return stop
+			})
+			if err != stop || calls != 1 {
+				t.Errorf("callback error: err = %v after %d call(s), want it returned after 1", err, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// stationIdPattern matches ICAO location indicators
+var stationIdPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{3}$`)
+
+// stationPattern matches the entries of a station list: ICAO location indicators and the patterns the data server
+// expands itself, @STATE, ~COUNTRY and identifier prefixes like PH*
+var stationPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]{3}|[@~][A-Za-z]{2}|[A-Za-z][A-Za-z0-9]{0,3}\*)$`)
+
+// validator collects the problems found while validating options
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, a ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, a...))
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+func (o QueryOptions) validate(v *validator) {
+	validateTimeRange(v, o.StartTime, o.EndTime, o.HoursBeforeNow)
+	validateArea(v, o.MinLat, o.MaxLat, o.MinLon, o.MaxLon)
+	o.RadialDistance.validate(v)
+	o.FlightPath.validate(v)
+}
+
+func (o StationOptions) validate(v *validator) {
+	validateStations(v, o.Stations)
+
+	if o.MinDegreeDistance < 0 {
+		v.addf("minDegreeDistance must not be negative")
+	}
+}
+
+// Validate checks the options for problems the data server would reject or silently ignore
+func (o MetarOptions) Validate() error {
+	var v validator
+	o.QueryOptions.validate(&v)
+	o.StationOptions.validate(&v)
+	return v.err()
+}
+
+// Validate checks the options for problems the data server would reject or silently ignore
+func (o TafOptions) Validate() error {
+	var v validator
+	o.QueryOptions.validate(&v)
+	o.StationOptions.validate(&v)
+
+	switch o.TimeType {
+	case "", "valid", "issue":
+	default:
+		v.addf("timeType %q is not one of valid, issue", o.TimeType)
+	}
+
+	return v.err()
+}
+
+// Validate checks the options for problems the data server would reject or silently ignore
+func (o AircraftReportOptions) Validate() error {
+	var v validator
+	o.QueryOptions.validate(&v)
+
+	if o.MinAltitudeFt < 0 || o.MaxAltitudeFt < 0 {
+		v.addf("minAltitudeFt and maxAltitudeFt must not be negative")
+	}
+	if o.MaxAltitudeFt > 0 && o.MinAltitudeFt > o.MaxAltitudeFt {
+		v.addf("minAltitudeFt %d is greater than maxAltitudeFt %d", o.MinAltitudeFt, o.MaxAltitudeFt)
+	}
+
+	return v.err()
+}
+
+// Validate checks the options for problems the data server would reject or silently ignore
+func (o AirSigmetOptions) Validate() error {
+	var v validator
+	validateTimeRange(&v, o.StartTime, o.EndTime, o.HoursBeforeNow)
+	return v.err()
+}
+
+// Validate checks the options for problems the data server would reject or silently ignore
+func (o GAirmetOptions) Validate() error {
+	var v validator
+	validateTimeRange(&v, o.StartTime, o.EndTime, o.HoursBeforeNow)
+	return v.err()
+}
+
+// Validate checks the options for problems the data server would reject or silently ignore
+func (o StationInfoOptions) Validate() error {
+	var v validator
+	validateStations(&v, o.Stations)
+	validateArea(&v, o.MinLat, o.MaxLat, o.MinLon, o.MaxLon)
+	o.RadialDistance.validate(&v)
+	o.FlightPath.validate(&v)
+	return v.err()
+}
+
+func validateStations(v *validator, stations []string) {
+	for _, station := range stations {
+		if !stationPattern.MatchString(station) {
+			v.addf("station %q is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern", station)
+		}
+	}
+}
+
+func validateTimeRange(v *validator, startTime, endTime timeValue, hoursBeforeNow int32) {
+	hasStart := !time.Time(startTime).IsZero()
+	hasEnd := !time.Time(endTime).IsZero()
+
+	if hoursBeforeNow < 0 {
+		v.addf("hoursBeforeNow must not be negative")
+	}
+	if hoursBeforeNow > 0 && (hasStart || hasEnd) {
+		v.addf("hoursBeforeNow cannot be combined with startTime/endTime")
+	}
+	if hasStart != hasEnd {
+		v.addf("startTime and endTime must be given together")
+	}
+	if hasStart && hasEnd && !time.Time(startTime).Before(time.Time(endTime)) {
+		v.addf("startTime must be before endTime")
+	}
+}
+
+// validateArea checks a bounding box, where all bounds being nil means there is none
+func validateArea(v *validator, minLat, maxLat, minLon, maxLon *float64) {
+	bounds := 0
+	for _, b := range []*float64{minLat, maxLat, minLon, maxLon} {
+		if b != nil {
+			bounds++
+		}
+	}
+	if bounds > 0 && bounds < 4 {
+		v.addf("bounding box needs all of minLat, maxLat, minLon and maxLon")
+	}
+	if bounds == 4 {
+		validateLatLon(v, "minLat/minLon", *minLat, *minLon)
+		validateLatLon(v, "maxLat/maxLon", *maxLat, *maxLon)
+		if *minLat > *maxLat {
+			v.addf("minLat %v is greater than maxLat %v", *minLat, *maxLat)
+		}
+		if *minLon > *maxLon {
+			v.addf("minLon %v is greater than maxLon %v", *minLon, *maxLon)
+		}
+	}
+}
+
+func validateLatLon(v *validator, name string, lat, lon float64) {
+	if lat < -90 || lat > 90 {
+		v.addf("%s latitude %v is outside -90..90", name, lat)
+	}
+	if lon < -180 || lon > 180 {
+		v.addf("%s longitude %v is outside -180..180", name, lon)
+	}
+}
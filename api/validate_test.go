@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options interface{ Validate() error }
+		want    []string // problems of the expected *ValidationError, nil if valid
+	}{
+		{"zero metar", MetarOptions{}, nil},
+		{
+			"station forms",
+			MetarOptions{StationOptions: StationOptions{Stations: []string{"KORD", "kmdw", "@WA", "~ca", "PH*", "K*", "EGL*"}}},
+			nil,
+		},
+		{
+			"bad stations",
+			MetarOptions{StationOptions: StationOptions{Stations: []string{"ORD", "KORD1", "@WAS", "*", "KORDX*", "K-RD"}}},
+			[]string{
+				`station "ORD" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+				`station "KORD1" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+				`station "@WAS" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+				`station "*" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+				`station "KORDX*" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+				`station "K-RD" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+			},
+		},
+		{
+			"bounding box on the equator and prime meridian",
+			MetarOptions{QueryOptions: QueryOptions{MinLat: Float(0), MaxLat: Float(10), MinLon: Float(-5), MaxLon: Float(0)}},
+			nil,
+		},
+		{
+			"half a bounding box",
+			MetarOptions{QueryOptions: QueryOptions{MinLat: Float(41)}},
+			[]string{"bounding box needs all of minLat, maxLat, minLon and maxLon"},
+		},
+		{
+			"inverted bounding box out of range",
+			StationInfoOptions{MinLat: Float(50), MaxLat: Float(40), MinLon: Float(-190), MaxLon: Float(-100)},
+			[]string{
+				"minLat/minLon longitude -190 is outside -180..180",
+				"minLat 50 is greater than maxLat 40",
+			},
+		},
+		{
+			"every problem is reported",
+			TafOptions{
+				QueryOptions: QueryOptions{
+					StartTime:      testTime("2026-10-18T06:00:00Z"),
+					HoursBeforeNow: 2,
+					RadialDistance: &RadialQuery{RadiusSM: -1, Lat: 91, Lon: 0},
+				},
+				StationOptions: StationOptions{Stations: []string{"KORD", "BAD!"}, MinDegreeDistance: -1},
+				TimeType:       "later",
+			},
+			[]string{
+				"hoursBeforeNow cannot be combined with startTime/endTime",
+				"startTime and endTime must be given together",
+				"radialDistance radius must be positive",
+				"radialDistance latitude 91 is outside -90..90",
+				`station "BAD!" is not a valid ICAO station identifier, @STATE, ~COUNTRY or PREFIX* pattern`,
+				"minDegreeDistance must not be negative",
+				`timeType "later" is not one of valid, issue`,
+			},
+		},
+		{
+			"flight path waypoints must be stations",
+			MetarOptions{QueryOptions: QueryOptions{FlightPath: &FlightPathQuery{MaxDistSM: 50, Waypoints: []Waypoint{{StationId: "@WA"}, {StationId: "KDEN"}}}}},
+			[]string{`flightPath waypoint "@WA" is not a valid ICAO station identifier`},
+		},
+		{
+			"zero radial distance is given",
+			StationInfoOptions{RadialDistance: &RadialQuery{}},
+			[]string{"radialDistance radius must be positive"},
+		},
+		{
+			"empty flight path is given",
+			MetarOptions{QueryOptions: QueryOptions{FlightPath: &FlightPathQuery{}}},
+			[]string{"flightPath maximum distance must be positive", "flightPath needs at least two waypoints"},
+		},
+		{
+			"time range order",
+			AirSigmetOptions{StartTime: testTime("2026-10-18T12:00:00Z"), EndTime: testTime("2026-10-18T06:00:00Z")},
+			[]string{"startTime must be before endTime"},
+		},
+		{
+			"altitudes",
+			AircraftReportOptions{MinAltitudeFt: 20000, MaxAltitudeFt: 10000},
+			[]string{"minAltitudeFt 20000 is greater than maxAltitudeFt 10000"},
+		},
+		{"negative hours", GAirmetOptions{HoursBeforeNow: -1}, []string{"hoursBeforeNow must not be negative"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() = %v, want *ValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Problems, tt.want) {
+				t.Errorf("Problems = %q, want %q", validationErr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Problems: []string{"first problem", "second problem"}}
+	if got, want := err.Error(), "invalid options: first problem; second problem"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/theperiscope/avwx/api"
+)
+
+// settings shared by all commands that talk to the Text Data Server
+var clientSettings struct {
+	rate     float64
+	cacheDir string
+	cacheTTL time.Duration
+}
+
+var clientBackend = api.NewEnumValue([]string{"adds", "data"}, "adds")
+
+// batchSize and batchWorkers split long station lists into requests short enough for the data server
+const (
+	batchSize    = 100
+	batchWorkers = 4
+)
+
+func newClient(opts ...api.ClientOption) (api.Client, error) {
+	opts = append(opts, api.WithBatching(batchSize, batchWorkers))
+
+	if clientSettings.rate > 0 {
+		opts = append(opts, api.WithRateLimit(clientSettings.rate, 1))
+	}
+
+	if len(clientSettings.cacheDir) > 0 {
+		cache, err := api.NewFileCache(clientSettings.cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, api.WithCache(cache, clientSettings.cacheTTL))
+	}
+
+	if clientBackend.String() == "data" {
+		opts = append(opts, api.WithBackend(api.BackendDataAPI))
+		return api.NewClient(api.DefaultDataApiEndPoint, opts...), nil
+	}
+
+	return api.NewClient(api.DefaultApiEndPoint, opts...), nil
+}
+
+// outputOptions returns the client options an output format needs. Only the JSON document formats have a place to
+// show ADDS errors and warnings, every other format fails on them instead: raw text and summaries have no room for
+// them and JSON lines are written while the response is read. CSV output also asks for the smaller CSV responses.
+func outputOptions(format string) []api.ClientOption {
+	if format == "json" || format == "json-pretty" {
+		return nil
+	}
+
+	opts := []api.ClientOption{api.WithADDSErrors(true)}
+	if format == "csv" {
+		opts = append(opts, api.WithFormat(api.FormatCSV))
+	}
+	return opts
+}
+
+// resetHoursBeforeNow clears the hoursBeforeNow default when an explicit time range is given because the two cannot
+// be combined
+func resetHoursBeforeNow(cmd *cobra.Command, hoursBeforeNow *int32) {
+	if (cmd.Flags().Changed("startTime") || cmd.Flags().Changed("endTime")) && !cmd.Flags().Changed("hoursBeforeNow") {
+		*hoursBeforeNow = 0
+	}
+}
+
+// resetMostRecentForEachStation clears the mostRecentForEachStation default for the Data API, which does not support
+// it. Given explicitly it is kept so the request fails instead of silently returning every report.
+func resetMostRecentForEachStation(cmd *cobra.Command, mostRecentForEachStation *bool) {
+	if clientBackend.String() == "data" && !cmd.Flags().Changed("mostRecentForEachStation") {
+		*mostRecentForEachStation = false
+	}
+}
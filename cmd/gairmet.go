@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/theperiscope/avwx/api"
+	"github.com/theperiscope/avwx/gairmets"
+)
+
+var gairmetCmd = &cobra.Command{
+	Use:     "gairmet",
+	Short:   "Get G-AIRMET data",
+	Long:    `Get Graphical AIRMET (G-AIRMET) data from the Aviation Weather Center's Text Data Server.`,
+	RunE:    gairmet,
+	Args:    cobra.MinimumNArgs(0),
+	Example: `   For examples and detailed description of all flags visit https://www.aviationweather.gov/dataserver/example?datatype=gairmet`,
+}
+
+var gairmetOptions api.GAirmetOptions
+var gairmetOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "summary"}, "summary")
+
+// client-side filters, the data server does not constrain G-AIRMETs by product, hazard or area
+var gairmetFilter struct {
+	products                       []string
+	hazards                        []string
+	forecastHours                  []int
+	minLat, maxLat, minLon, maxLon float64
+	lat, lon                       float64
+}
+
+func gairmet(cmd *cobra.Command, args []string) (err error) {
+
+	resetHoursBeforeNow(cmd, &gairmetOptions.HoursBeforeNow)
+	if err = gairmetOptions.Validate(); err != nil {
+		return
+	}
+
+	hasBox, err := changedTogether(cmd, "minLat", "maxLat", "minLon", "maxLon")
+	if err != nil {
+		return
+	}
+	hasPoint, err := changedTogether(cmd, "lat", "lon")
+	if err != nil {
+		return
+	}
+
+	client, err := newClient(outputOptions(gairmetOutputFormat.String())...)
+	if err != nil {
+		return
+	}
+
+	data, err := client.GetGAirmets(gairmetOptions)
+
+	if err != nil {
+		return
+	}
+
+	f := gairmetFilter
+	data.Filter(func(g *gairmets.GAirmet) bool {
+		if len(f.products) > 0 && !containsFold(f.products, g.Product) {
+			return false
+		}
+		if len(f.hazards) > 0 && !containsFold(f.hazards, g.Hazard.Type) {
+			return false
+		}
+		if len(f.forecastHours) > 0 && !containsInt(f.forecastHours, int(g.ForecastHour)) {
+			return false
+		}
+		if hasBox && !g.Intersects(f.minLat, f.minLon, f.maxLat, f.maxLon) {
+			return false
+		}
+		if hasPoint && !g.Contains(f.lat, f.lon) {
+			return false
+		}
+		return true
+	})
+
+	switch gairmetOutputFormat.String() {
+	case "json":
+		s, e := data.ToJson()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "json-pretty":
+		s, e := data.ToJsonIndented()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "summary":
+		fmt.Println(strings.Join(data.ToSummary(), "\n"))
+	default:
+		err = fmt.Errorf("invalid G-AIRMET output format '%s'", gairmetOutputFormat)
+		return
+	}
+
+	return nil
+}
+
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	gairmetCmd.Flags().SortFlags = false
+
+	gairmetCmd.Flags().Var(&gairmetOptions.StartTime, "startTime", "")
+	gairmetCmd.Flags().Var(&gairmetOptions.EndTime, "endTime", "")
+	gairmetCmd.Flags().Int32Var(&gairmetOptions.HoursBeforeNow, "hoursBeforeNow", 1, "")
+	gairmetCmd.Flags().StringSliceVar(&gairmetOptions.Fields, "fields", []string{}, "")
+
+	gairmetCmd.Flags().StringSliceVar(&gairmetFilter.products, "product", []string{}, "only show these products: SIERRA, TANGO, ZULU")
+	gairmetCmd.Flags().StringSliceVar(&gairmetFilter.hazards, "hazard", []string{}, "only show these hazard types, e.g. IFR, MT_OBSC, TURB-HI, TURB-LO, ICE, FZLVL, M_FZLVL, SFC_WND, LLWS")
+	gairmetCmd.Flags().IntSliceVar(&gairmetFilter.forecastHours, "forecastHour", []int{}, "only show these forecast hours, e.g. 0,3,6")
+	gairmetCmd.Flags().Float64Var(&gairmetFilter.minLat, "minLat", 0, "only show areas intersecting the bounding box")
+	gairmetCmd.Flags().Float64Var(&gairmetFilter.maxLat, "maxLat", 0, "")
+	gairmetCmd.Flags().Float64Var(&gairmetFilter.minLon, "minLon", 0, "")
+	gairmetCmd.Flags().Float64Var(&gairmetFilter.maxLon, "maxLon", 0, "")
+	gairmetCmd.Flags().Float64Var(&gairmetFilter.lat, "lat", 0, "only show areas containing the point")
+	gairmetCmd.Flags().Float64Var(&gairmetFilter.lon, "lon", 0, "")
+
+	gairmetCmd.Flags().Var(gairmetOutputFormat, "output", "")
+}
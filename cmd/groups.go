@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/theperiscope/avwx/stations"
+)
+
+// groupsFile is the JSON file with the named station groups usable in --stations, an object mapping each group name
+// to its entries, e.g. {"home": ["KORD", "KMDW", "@WI"]}
+var groupsFile string
+
+// defaultGroupsFile returns the groups file used when --groups is not given
+func defaultGroupsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "avwx", "groups.json")
+}
+
+func loadGroups() (map[string][]string, error) {
+	path := groupsFile
+	if len(path) == 0 {
+		path = defaultGroupsFile()
+	}
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && len(groupsFile) == 0 {
+		// the default groups file is optional
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var groups map[string][]string
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("reading station groups from %s: %w", path, err)
+	}
+	return groups, nil
+}
+
+// expandStations replaces named groups in a --stations list by their members. The legacy Text Data Server expands
+// @STATE, ~COUNTRY and PREFIX* itself, for the Data API they are looked up in the embedded station catalog. They fail
+// while that is the seed catalog, which would expand them to a few airports only.
+func expandStations(entries []string) ([]string, error) {
+	groups, err := loadGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	native := clientBackend.String() == "adds"
+	e := stations.Expander{
+		Groups:        groups,
+		PassStates:    native,
+		PassCountries: native,
+		PassPrefixes:  native,
+	}
+	if !native {
+		if e.Index, err = stations.Catalog(); err != nil {
+			return nil, err
+		}
+		e.IndexIncomplete = !stations.CatalogComplete()
+	}
+
+	expanded, err := e.Expand(entries)
+	if errors.Is(err, stations.ErrIndexIncomplete) {
+		return nil, fmt.Errorf("%w, use --api adds to have the data server expand @STATE, ~COUNTRY and PREFIX*", err)
+	}
+	return expanded, err
+}
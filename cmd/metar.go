@@ -1,12 +1,14 @@
 package cmd
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/theperiscope/avwx/api"
+	"github.com/theperiscope/avwx/metars"
 )
 
 var metarCmd = &cobra.Command{
@@ -19,17 +21,51 @@ var metarCmd = &cobra.Command{
 }
 
 var metarOptions api.MetarOptions
-var metarOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "rawtextonly"}, "rawtextonly")
+var decodeRemarks bool
+var metarOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "json-lines", "rawtextonly", "csv"}, "rawtextonly")
 
 func metar(cmd *cobra.Command, args []string) (err error) {
 
-	client := api.NewClient(api.DefaultApiEndPoint)
+	resetHoursBeforeNow(cmd, &metarOptions.HoursBeforeNow)
+	resetMostRecentForEachStation(cmd, &metarOptions.MostRecentForEachStation)
+	if metarOptions.Stations, err = expandStations(metarOptions.Stations); err != nil {
+		return
+	}
+	if err = metarOptions.Validate(); err != nil {
+		return
+	}
+
+	client, err := newClient(outputOptions(metarOutputFormat.String())...)
+	if err != nil {
+		return
+	}
+
+	if metarOutputFormat.String() == "json-lines" {
+		return client.StreamMetars(context.Background(), metarOptions, func(m metars.Metar) error {
+			if decodeRemarks {
+				m.DecodeRemarks()
+			}
+			b, e := json.Marshal(m)
+			if e != nil {
+				return e
+			}
+			fmt.Println(string(b))
+			return nil
+		})
+	}
+
 	data, err := client.GetMetar(metarOptions)
 
 	if err != nil {
 		return
 	}
 
+	if decodeRemarks {
+		for i := range data.Data.Metars {
+			data.Data.Metars[i].DecodeRemarks()
+		}
+	}
+
 	switch metarOutputFormat.String() {
 	case "json":
 		s, e := data.ToJson()
@@ -43,15 +79,13 @@ func metar(cmd *cobra.Command, args []string) (err error) {
 			return e
 		}
 		fmt.Println(s)
-	case "rawtextonly":
-		if len(data.Errors) > 0 {
-			return errors.New("ADDS error(s): " + strings.Join(data.Errors, "\n"))
-		}
-
-		if len(data.Warnings) > 0 {
-			return errors.New("ADDS warnings(s): " + strings.Join(data.Warnings, "\n"))
+	case "csv":
+		s, e := data.ToCsv()
+		if e != nil {
+			return e
 		}
-
+		fmt.Print(s)
+	case "rawtextonly":
 		fmt.Println(strings.Join(data.ToRawTextOnly(), "\n"))
 	default:
 		err = fmt.Errorf("invalid METAR output format '%s'", metarOutputFormat)
@@ -64,21 +98,22 @@ func metar(cmd *cobra.Command, args []string) (err error) {
 func init() {
 	metarCmd.Flags().SortFlags = false
 
-	metarCmd.Flags().StringSliceVar(&metarOptions.Stations, "stations", []string{}, "")
+	metarCmd.Flags().StringSliceVar(&metarOptions.Stations, "stations", []string{}, "station identifiers, @STATE, ~COUNTRY, PREFIX* or names of groups from --groups")
 	metarCmd.MarkFlagRequired("stations")
 	metarCmd.Flags().Var(&metarOptions.StartTime, "startTime", "")
 	metarCmd.Flags().Var(&metarOptions.EndTime, "endTime", "")
 	metarCmd.Flags().Int32Var(&metarOptions.HoursBeforeNow, "hoursBeforeNow", 6, "")
 	metarCmd.Flags().BoolVar(&metarOptions.MostRecent, "mostRecent", false, "")
 	metarCmd.Flags().BoolVar(&metarOptions.MostRecentForEachStation, "mostRecentForEachStation", true, "")
-	metarCmd.Flags().Float64Var(&metarOptions.MinLat, "minLat", 0, "")
-	metarCmd.Flags().Float64Var(&metarOptions.MaxLat, "maxLat", 0, "")
-	metarCmd.Flags().Float64Var(&metarOptions.MinLon, "minLon", 0, "")
-	metarCmd.Flags().Float64Var(&metarOptions.MaxLon, "maxLon", 0, "")
-	metarCmd.Flags().StringVar(&metarOptions.RadialDistance, "radialDistance", "", "")
-	metarCmd.Flags().StringSliceVar(&metarOptions.FlightPath, "flightPath", []string{}, "")
+	metarCmd.Flags().Var(api.NewFloatValue(&metarOptions.MinLat), "minLat", "")
+	metarCmd.Flags().Var(api.NewFloatValue(&metarOptions.MaxLat), "maxLat", "")
+	metarCmd.Flags().Var(api.NewFloatValue(&metarOptions.MinLon), "minLon", "")
+	metarCmd.Flags().Var(api.NewFloatValue(&metarOptions.MaxLon), "maxLon", "")
+	metarCmd.Flags().Var(api.NewRadialQueryValue(&metarOptions.RadialDistance), "radialDistance", "")
+	metarCmd.Flags().Var(api.NewFlightPathQueryValue(&metarOptions.FlightPath), "flightPath", "")
 	metarCmd.Flags().Float64Var(&metarOptions.MinDegreeDistance, "minDegreeDistance", 0, "")
 	metarCmd.Flags().StringSliceVar(&metarOptions.Fields, "fields", []string{}, "")
 
 	metarCmd.Flags().Var(metarOutputFormat, "output", "")
+	metarCmd.Flags().BoolVar(&decodeRemarks, "decode-remarks", false, "add the decoded RMK section to JSON output")
 }
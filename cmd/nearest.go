@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/theperiscope/avwx/api"
+	"github.com/theperiscope/avwx/stations"
+)
+
+var nearestCmd = &cobra.Command{
+	Use:   "nearest",
+	Short: "Find the nearest stations",
+	Long:  `Find the stations nearest to a location using the station catalog built into AVWX, without a network round trip.`,
+	RunE:  nearest,
+	Args:  cobra.MinimumNArgs(0),
+	Example: `   avwx nearest --lat 41.9 --lon -87.7 --count 3 --has-taf
+   avwx nearest --lat 41.9 --lon -87.7 --radius 50`,
+}
+
+var nearestOptions struct {
+	lat, lon float64
+	count    int
+	radiusSM float64
+	hasMetar bool
+	hasTaf   bool
+	hasRaob  bool
+}
+var nearestOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "summary"}, "summary")
+
+func nearest(cmd *cobra.Command, args []string) (err error) {
+
+	o := nearestOptions
+	if o.lat < -90 || o.lat > 90 || o.lon < -180 || o.lon > 180 {
+		return errors.New("lat must be within -90..90 and lon within -180..180")
+	}
+	if o.count < 1 {
+		return errors.New("count must be at least 1")
+	}
+	if o.radiusSM < 0 {
+		return errors.New("radius must not be negative")
+	}
+
+	catalog, err := stations.Catalog()
+	if err != nil {
+		return
+	}
+	if !stations.CatalogComplete() {
+		fmt.Fprintln(os.Stderr, "warning: the built-in station catalog only holds major airports, nearer stations may be missing")
+	}
+
+	var products []string
+	if o.hasMetar {
+		products = append(products, "METAR")
+	}
+	if o.hasTaf {
+		products = append(products, "TAF")
+	}
+	if o.hasRaob {
+		products = append(products, "rawinsonde")
+	}
+	filter := stations.HasProducts(products...)
+
+	var results []stations.Result
+	if cmd.Flags().Changed("radius") {
+		results = catalog.Within(o.lat, o.lon, o.radiusSM, filter)
+		if cmd.Flags().Changed("count") && o.count > 0 && len(results) > o.count {
+			results = results[:o.count]
+		}
+	} else {
+		results = catalog.Nearest(o.lat, o.lon, o.count, filter)
+	}
+
+	switch nearestOutputFormat.String() {
+	case "json":
+		b, e := json.Marshal(results)
+		if e != nil {
+			return e
+		}
+		fmt.Println(string(b))
+	case "json-pretty":
+		b, e := json.MarshalIndent(results, "", "  ")
+		if e != nil {
+			return e
+		}
+		fmt.Println(string(b))
+	case "summary":
+		for _, r := range results {
+			fmt.Printf("%7.1fsm %s\n", r.DistanceSM, r.Station.Summary())
+		}
+	default:
+		err = fmt.Errorf("invalid nearest output format '%s'", nearestOutputFormat)
+		return
+	}
+
+	return nil
+}
+
+func init() {
+	nearestCmd.Flags().SortFlags = false
+
+	nearestCmd.Flags().Float64Var(&nearestOptions.lat, "lat", 0, "")
+	nearestCmd.MarkFlagRequired("lat")
+	nearestCmd.Flags().Float64Var(&nearestOptions.lon, "lon", 0, "")
+	nearestCmd.MarkFlagRequired("lon")
+	nearestCmd.Flags().IntVar(&nearestOptions.count, "count", 5, "maximum number of stations to show")
+	nearestCmd.Flags().Float64Var(&nearestOptions.radiusSM, "radius", 0, "show all stations within this many statute miles instead of the nearest count")
+	nearestCmd.Flags().BoolVar(&nearestOptions.hasMetar, "has-metar", false, "only show stations issuing METARs")
+	nearestCmd.Flags().BoolVar(&nearestOptions.hasTaf, "has-taf", false, "only show stations issuing TAFs")
+	nearestCmd.Flags().BoolVar(&nearestOptions.hasRaob, "has-rawinsonde", false, "only show stations launching rawinsondes")
+
+	nearestCmd.Flags().Var(nearestOutputFormat, "output", "")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/theperiscope/avwx/api"
+)
+
+var pirepCmd = &cobra.Command{
+	Use:     "pirep",
+	Short:   "Get PIREP (aircraft report) data",
+	Long:    `Get PIREP and AIREP (aircraft report) data from the Aviation Weather Center's Text Data Server.`,
+	RunE:    pirep,
+	Args:    cobra.MinimumNArgs(0),
+	Example: `   For examples and detailed description of all flags visit https://www.aviationweather.gov/dataserver/example?datatype=pirep`,
+}
+
+var pirepOptions api.AircraftReportOptions
+var pirepOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "rawtextonly"}, "rawtextonly")
+
+func pirep(cmd *cobra.Command, args []string) (err error) {
+
+	resetHoursBeforeNow(cmd, &pirepOptions.HoursBeforeNow)
+	if err = pirepOptions.Validate(); err != nil {
+		return
+	}
+
+	client, err := newClient(outputOptions(pirepOutputFormat.String())...)
+	if err != nil {
+		return
+	}
+
+	data, err := client.GetAircraftReports(pirepOptions)
+
+	if err != nil {
+		return
+	}
+
+	switch pirepOutputFormat.String() {
+	case "json":
+		s, e := data.ToJson()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "json-pretty":
+		s, e := data.ToJsonIndented()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "rawtextonly":
+		fmt.Println(strings.Join(data.ToRawTextOnly(), "\n"))
+	default:
+		err = fmt.Errorf("invalid PIREP output format '%s'", pirepOutputFormat)
+		return
+	}
+
+	return nil
+}
+
+func init() {
+	pirepCmd.Flags().SortFlags = false
+
+	pirepCmd.Flags().Var(&pirepOptions.StartTime, "startTime", "")
+	pirepCmd.Flags().Var(&pirepOptions.EndTime, "endTime", "")
+	pirepCmd.Flags().Int32Var(&pirepOptions.HoursBeforeNow, "hoursBeforeNow", 3, "")
+	pirepCmd.Flags().BoolVar(&pirepOptions.MostRecent, "mostRecent", false, "")
+	pirepCmd.Flags().Var(api.NewFloatValue(&pirepOptions.MinLat), "minLat", "")
+	pirepCmd.Flags().Var(api.NewFloatValue(&pirepOptions.MaxLat), "maxLat", "")
+	pirepCmd.Flags().Var(api.NewFloatValue(&pirepOptions.MinLon), "minLon", "")
+	pirepCmd.Flags().Var(api.NewFloatValue(&pirepOptions.MaxLon), "maxLon", "")
+	pirepCmd.Flags().Var(api.NewRadialQueryValue(&pirepOptions.RadialDistance), "radialDistance", "")
+	pirepCmd.Flags().Var(api.NewFlightPathQueryValue(&pirepOptions.FlightPath), "flightPath", "")
+	pirepCmd.Flags().Int32Var(&pirepOptions.MinAltitudeFt, "minAltitudeFt", 0, "")
+	pirepCmd.Flags().Int32Var(&pirepOptions.MaxAltitudeFt, "maxAltitudeFt", 0, "")
+	pirepCmd.Flags().StringSliceVar(&pirepOptions.Fields, "fields", []string{}, "")
+
+	pirepCmd.Flags().Var(pirepOutputFormat, "output", "")
+}
@@ -1,14 +1,16 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
 var (
 	rootCmd = &cobra.Command{
 		Use:           "avwx",
-		Short:         "A simple tool to access METAR and TAF data.",
-		Long:          "AVWX is a tool to access aviation weather data (METARs, TAFs) provided by the Aviation Weather Center's Text Data Server.",
+		Short:         "A simple tool to access METAR, TAF, PIREP and AIRMET/SIGMET/G-AIRMET data.",
+		Long:          "AVWX is a tool to access aviation weather data (METARs, TAFs, PIREPs, AIRMETs/SIGMETs, G-AIRMETs) provided by the Aviation Weather Center's Text Data Server.",
 		SilenceErrors: true,
 	}
 )
@@ -16,8 +18,18 @@ var (
 func init() {
 
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().Var(clientBackend, "api", "web service to use: adds (legacy Text Data Server) or data (JSON Data API, METARs and TAFs only)")
+	rootCmd.PersistentFlags().Float64Var(&clientSettings.rate, "rate", 0, "maximum number of requests per second sent to the data server (0 = unlimited)")
+	rootCmd.PersistentFlags().StringVar(&clientSettings.cacheDir, "cache-dir", "", "directory to cache data server responses in (empty = no caching)")
+	rootCmd.PersistentFlags().DurationVar(&clientSettings.cacheTTL, "cache-ttl", 10*time.Minute, "how long cached responses stay valid")
+	rootCmd.PersistentFlags().StringVar(&groupsFile, "groups", "", "JSON file with named station groups (default "+defaultGroupsFile()+")")
 	rootCmd.AddCommand(metarCmd)
 	rootCmd.AddCommand(tafCmd)
+	rootCmd.AddCommand(pirepCmd)
+	rootCmd.AddCommand(sigmetCmd)
+	rootCmd.AddCommand(gairmetCmd)
+	rootCmd.AddCommand(stationCmd)
+	rootCmd.AddCommand(nearestCmd)
 
 	// remove default help command
 	rootCmd.SetHelpCommand(&cobra.Command{
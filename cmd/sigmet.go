@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/theperiscope/avwx/airsigmets"
+	"github.com/theperiscope/avwx/api"
+)
+
+var sigmetCmd = &cobra.Command{
+	Use:     "sigmet",
+	Short:   "Get AIRMET/SIGMET data",
+	Long:    `Get AIRMET and SIGMET data from the Aviation Weather Center's Text Data Server.`,
+	RunE:    sigmet,
+	Args:    cobra.MinimumNArgs(0),
+	Example: `   For examples and detailed description of all flags visit https://www.aviationweather.gov/dataserver/example?datatype=airsigmet`,
+}
+
+var sigmetOptions api.AirSigmetOptions
+var sigmetOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "rawtextonly"}, "rawtextonly")
+
+// client-side filters, the data server does not constrain AIRMETs/SIGMETs by hazard or area
+var sigmetFilter struct {
+	hazards                        []string
+	minLat, maxLat, minLon, maxLon float64
+	lat, lon                       float64
+}
+
+func sigmet(cmd *cobra.Command, args []string) (err error) {
+
+	resetHoursBeforeNow(cmd, &sigmetOptions.HoursBeforeNow)
+	if err = sigmetOptions.Validate(); err != nil {
+		return
+	}
+
+	hasBox, err := changedTogether(cmd, "minLat", "maxLat", "minLon", "maxLon")
+	if err != nil {
+		return
+	}
+	hasPoint, err := changedTogether(cmd, "lat", "lon")
+	if err != nil {
+		return
+	}
+
+	client, err := newClient(outputOptions(sigmetOutputFormat.String())...)
+	if err != nil {
+		return
+	}
+
+	data, err := client.GetAirSigmets(sigmetOptions)
+
+	if err != nil {
+		return
+	}
+
+	f := sigmetFilter
+	data.Filter(func(a *airsigmets.AirSigmet) bool {
+		if len(f.hazards) > 0 && !containsFold(f.hazards, a.Hazard.Type) {
+			return false
+		}
+		if hasBox && !a.Intersects(f.minLat, f.minLon, f.maxLat, f.maxLon) {
+			return false
+		}
+		if hasPoint && !a.Contains(f.lat, f.lon) {
+			return false
+		}
+		return true
+	})
+
+	switch sigmetOutputFormat.String() {
+	case "json":
+		s, e := data.ToJson()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "json-pretty":
+		s, e := data.ToJsonIndented()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "rawtextonly":
+		fmt.Println(strings.Join(data.ToRawTextOnly(), "\n\n"))
+	default:
+		err = fmt.Errorf("invalid AIRMET/SIGMET output format '%s'", sigmetOutputFormat)
+		return
+	}
+
+	return nil
+}
+
+// changedTogether reports whether all of the named flags were given, and fails if only some of them were
+func changedTogether(cmd *cobra.Command, names ...string) (bool, error) {
+	changed := 0
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			changed++
+		}
+	}
+
+	if changed > 0 && changed < len(names) {
+		return false, errors.New("flags " + strings.Join(names, ", ") + " must be given together")
+	}
+
+	return changed == len(names), nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	sigmetCmd.Flags().SortFlags = false
+
+	sigmetCmd.Flags().Var(&sigmetOptions.StartTime, "startTime", "")
+	sigmetCmd.Flags().Var(&sigmetOptions.EndTime, "endTime", "")
+	sigmetCmd.Flags().Int32Var(&sigmetOptions.HoursBeforeNow, "hoursBeforeNow", 1, "")
+	sigmetCmd.Flags().StringSliceVar(&sigmetOptions.Fields, "fields", []string{}, "")
+
+	sigmetCmd.Flags().StringSliceVar(&sigmetFilter.hazards, "hazard", []string{}, "only show these hazard types, e.g. CONVECTIVE, TURB, ICE, IFR, MTN OBSCN, ASH")
+	sigmetCmd.Flags().Float64Var(&sigmetFilter.minLat, "minLat", 0, "only show areas intersecting the bounding box")
+	sigmetCmd.Flags().Float64Var(&sigmetFilter.maxLat, "maxLat", 0, "")
+	sigmetCmd.Flags().Float64Var(&sigmetFilter.minLon, "minLon", 0, "")
+	sigmetCmd.Flags().Float64Var(&sigmetFilter.maxLon, "maxLon", 0, "")
+	sigmetCmd.Flags().Float64Var(&sigmetFilter.lat, "lat", 0, "only show areas containing the point")
+	sigmetCmd.Flags().Float64Var(&sigmetFilter.lon, "lon", 0, "")
+
+	sigmetCmd.Flags().Var(sigmetOutputFormat, "output", "")
+}
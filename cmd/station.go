@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/theperiscope/avwx/api"
+)
+
+var stationCmd = &cobra.Command{
+	Use:     "station",
+	Short:   "Get station information",
+	Long:    `Get station information (name, location, elevation and issued products) from the Aviation Weather Center's Text Data Server.`,
+	RunE:    station,
+	Args:    cobra.MinimumNArgs(0),
+	Example: `   For examples and detailed description of all flags visit https://www.aviationweather.gov/dataserver/example?datatype=station`,
+}
+
+var stationOptions api.StationInfoOptions
+var stationOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "summary"}, "summary")
+
+func station(cmd *cobra.Command, args []string) (err error) {
+
+	if err = stationOptions.Validate(); err != nil {
+		return
+	}
+
+	client, err := newClient(outputOptions(stationOutputFormat.String())...)
+	if err != nil {
+		return
+	}
+
+	data, err := client.GetStations(stationOptions)
+
+	if err != nil {
+		return
+	}
+
+	switch stationOutputFormat.String() {
+	case "json":
+		s, e := data.ToJson()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "json-pretty":
+		s, e := data.ToJsonIndented()
+		if e != nil {
+			return e
+		}
+		fmt.Println(s)
+	case "summary":
+		fmt.Println(strings.Join(data.ToSummary(), "\n"))
+	default:
+		err = fmt.Errorf("invalid station output format '%s'", stationOutputFormat)
+		return
+	}
+
+	return nil
+}
+
+func init() {
+	stationCmd.Flags().SortFlags = false
+
+	stationCmd.Flags().StringSliceVar(&stationOptions.Stations, "stations", []string{}, "")
+	stationCmd.Flags().Var(api.NewFloatValue(&stationOptions.MinLat), "minLat", "")
+	stationCmd.Flags().Var(api.NewFloatValue(&stationOptions.MaxLat), "maxLat", "")
+	stationCmd.Flags().Var(api.NewFloatValue(&stationOptions.MinLon), "minLon", "")
+	stationCmd.Flags().Var(api.NewFloatValue(&stationOptions.MaxLon), "maxLon", "")
+	stationCmd.Flags().Var(api.NewRadialQueryValue(&stationOptions.RadialDistance), "radialDistance", "")
+	stationCmd.Flags().Var(api.NewFlightPathQueryValue(&stationOptions.FlightPath), "flightPath", "")
+	stationCmd.Flags().StringSliceVar(&stationOptions.Fields, "fields", []string{}, "")
+
+	stationCmd.Flags().Var(stationOutputFormat, "output", "")
+}
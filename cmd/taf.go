@@ -1,12 +1,14 @@
 package cmd
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/theperiscope/avwx/api"
+	"github.com/theperiscope/avwx/tafs"
 )
 
 var tafCmd = &cobra.Command{
@@ -19,11 +21,35 @@ var tafCmd = &cobra.Command{
 }
 
 var tafOptions api.TafOptions
-var tafOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "rawtextonly", "rawtextonly-pretty"}, "rawtextonly-pretty")
+var tafOutputFormat = api.NewEnumValue([]string{"json", "json-pretty", "json-lines", "rawtextonly", "rawtextonly-pretty", "csv"}, "rawtextonly-pretty")
 
 func taf(cmd *cobra.Command, args []string) (err error) {
 
-	client := api.NewClient(api.DefaultApiEndPoint)
+	resetHoursBeforeNow(cmd, &tafOptions.HoursBeforeNow)
+	resetMostRecentForEachStation(cmd, &tafOptions.MostRecentForEachStation)
+	if tafOptions.Stations, err = expandStations(tafOptions.Stations); err != nil {
+		return
+	}
+	if err = tafOptions.Validate(); err != nil {
+		return
+	}
+
+	client, err := newClient(outputOptions(tafOutputFormat.String())...)
+	if err != nil {
+		return
+	}
+
+	if tafOutputFormat.String() == "json-lines" {
+		return client.StreamTafs(context.Background(), tafOptions, func(t tafs.Taf) error {
+			b, e := json.Marshal(t)
+			if e != nil {
+				return e
+			}
+			fmt.Println(string(b))
+			return nil
+		})
+	}
+
 	data, err := client.GetTaf(tafOptions)
 
 	if err != nil {
@@ -43,16 +69,13 @@ func taf(cmd *cobra.Command, args []string) (err error) {
 			return e
 		}
 		fmt.Println(s)
-	case "rawtextonly":
-	case "rawtextonly-pretty":
-		if len(data.Errors) > 0 {
-			return errors.New("ADDS error(s): " + strings.Join(data.Errors, "\n"))
-		}
-
-		if len(data.Warnings) > 0 {
-			return errors.New("ADDS warnings(s): " + strings.Join(data.Warnings, "\n"))
+	case "csv":
+		s, e := data.ToCsv()
+		if e != nil {
+			return e
 		}
-
+		fmt.Print(s)
+	case "rawtextonly", "rawtextonly-pretty":
 		if tafOutputFormat.String() == "rawtextonly-pretty" {
 			fmt.Println(strings.Replace(strings.Join(data.ToRawTextOnly(), "\n"), " FM", "\n  FM", -1))
 		} else {
@@ -70,7 +93,7 @@ func taf(cmd *cobra.Command, args []string) (err error) {
 func init() {
 	tafCmd.Flags().SortFlags = false
 
-	tafCmd.Flags().StringSliceVar(&tafOptions.Stations, "stations", []string{}, "")
+	tafCmd.Flags().StringSliceVar(&tafOptions.Stations, "stations", []string{}, "station identifiers, @STATE, ~COUNTRY, PREFIX* or names of groups from --groups")
 	tafCmd.MarkFlagRequired("stations")
 	tafCmd.Flags().Var(&tafOptions.StartTime, "startTime", "")
 	tafCmd.Flags().Var(&tafOptions.EndTime, "endTime", "")
@@ -78,12 +101,12 @@ func init() {
 	tafCmd.Flags().Int32Var(&tafOptions.HoursBeforeNow, "hoursBeforeNow", 6, "")
 	tafCmd.Flags().BoolVar(&tafOptions.MostRecent, "mostRecent", false, "")
 	tafCmd.Flags().BoolVar(&tafOptions.MostRecentForEachStation, "mostRecentForEachStation", true, "")
-	tafCmd.Flags().Float64Var(&tafOptions.MinLat, "minLat", 0, "")
-	tafCmd.Flags().Float64Var(&tafOptions.MaxLat, "maxLat", 0, "")
-	tafCmd.Flags().Float64Var(&tafOptions.MinLon, "minLon", 0, "")
-	tafCmd.Flags().Float64Var(&tafOptions.MaxLon, "maxLon", 0, "")
-	tafCmd.Flags().StringVar(&tafOptions.RadialDistance, "radialDistance", "", "")
-	tafCmd.Flags().StringSliceVar(&tafOptions.FlightPath, "flightPath", []string{}, "")
+	tafCmd.Flags().Var(api.NewFloatValue(&tafOptions.MinLat), "minLat", "")
+	tafCmd.Flags().Var(api.NewFloatValue(&tafOptions.MaxLat), "maxLat", "")
+	tafCmd.Flags().Var(api.NewFloatValue(&tafOptions.MinLon), "minLon", "")
+	tafCmd.Flags().Var(api.NewFloatValue(&tafOptions.MaxLon), "maxLon", "")
+	tafCmd.Flags().Var(api.NewRadialQueryValue(&tafOptions.RadialDistance), "radialDistance", "")
+	tafCmd.Flags().Var(api.NewFlightPathQueryValue(&tafOptions.FlightPath), "flightPath", "")
 	tafCmd.Flags().Float64Var(&tafOptions.MinDegreeDistance, "minDegreeDistance", 0, "")
 	tafCmd.Flags().StringSliceVar(&tafOptions.Fields, "fields", []string{}, "")
 
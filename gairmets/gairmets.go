@@ -0,0 +1,140 @@
+package gairmets
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/theperiscope/avwx/geo"
+)
+
+type Response struct {
+	XMLName      xml.Name   `xml:"response" json:"-"`
+	Version      string     `xml:"version,attr"`
+	RequestIndex int32      `xml:"request_index"`
+	Errors       []string   `xml:"errors>error"`
+	Warnings     []string   `xml:"warnings>warning"`
+	TimeTakenMs  int32      `xml:"time_taken_ms"`
+	DataSource   DataSource `xml:"data_source"`
+	Request      Request    `xml:"request"`
+	Data         Data       `xml:"data"`
+}
+
+type Request struct {
+	XMLName xml.Name `xml:"request" json:"-"`
+	Type    string   `xml:"type,attr"`
+}
+
+type DataSource struct {
+	XMLName xml.Name `xml:"data_source" json:"-"`
+	Name    string   `xml:"name,attr"`
+}
+
+type Data struct {
+	XMLName    xml.Name  `xml:"data" json:"-"`
+	NumResults int32     `xml:"num_results,attr"`
+	GAirmets   []GAirmet `xml:"GAIRMET"`
+}
+
+type Hazard struct {
+	XMLName  xml.Name `xml:"hazard" json:"-"`
+	Type     string   `xml:"type,attr"`
+	Severity string   `xml:"severity,attr"`
+}
+
+type Altitude struct {
+	XMLName    xml.Name `xml:"altitude" json:"-"`
+	MinFtMSL   string   `xml:"min_ft_msl,attr"` // a height or "SFC"/"FZL"
+	MaxFtMSL   string   `xml:"max_ft_msl,attr"`
+	LevelFtMSL string   `xml:"level_ft_msl,attr"`
+}
+
+type FzlAltitude struct {
+	XMLName  xml.Name `xml:"fzl_altitude" json:"-"`
+	MinFtMSL string   `xml:"min_ft_msl,attr"`
+	MaxFtMSL string   `xml:"max_ft_msl,attr"`
+}
+
+// Point and Area are the geo package types shared with the other area products
+type Point = geo.AreaPoint
+
+type Area = geo.Area
+
+type GAirmet struct {
+	XMLName      xml.Name     `xml:"GAIRMET" json:"-"`
+	ReceiptTime  time.Time    `xml:"receipt_time"`
+	IssueTime    time.Time    `xml:"issue_time"`
+	ExpireTime   time.Time    `xml:"expire_time"`
+	ValidTime    time.Time    `xml:"valid_time"`
+	Product      string       `xml:"product"` // SIERRA, TANGO or ZULU
+	Tag          string       `xml:"tag"`
+	ForecastHour int32        `xml:"forecast_hour"`
+	Hazard       Hazard       `xml:"hazard"`
+	GeometryType string       `xml:"geometry_type"`
+	DueTo        string       `xml:"due_to"`
+	Altitude     []Altitude   `xml:"altitude"`
+	FzlAltitude  *FzlAltitude `xml:"fzl_altitude"`
+	Area         Area         `xml:"area"`
+}
+
+// Contains reports whether the point lies inside the G-AIRMET area
+func (g *GAirmet) Contains(lat, lon float64) bool {
+	return g.Area.Contains(lat, lon)
+}
+
+// Intersects reports whether the G-AIRMET area overlaps the bounding box
+func (g *GAirmet) Intersects(minLat, minLon, maxLat, maxLon float64) bool {
+	return g.Area.Intersects(minLat, minLon, maxLat, maxLon)
+}
+
+// Filter removes all G-AIRMETs for which keep returns false
+func (r *Response) Filter(keep func(*GAirmet) bool) {
+	kept := r.Data.GAirmets[:0]
+	for i := range r.Data.GAirmets {
+		if keep(&r.Data.GAirmets[i]) {
+			kept = append(kept, r.Data.GAirmets[i])
+		}
+	}
+	r.Data.GAirmets = kept
+	r.Data.NumResults = int32(len(kept))
+}
+
+// ToSummary returns one line per G-AIRMET since, unlike other products, G-AIRMETs have no raw text
+func (r *Response) ToSummary() (s []string) {
+	for _, g := range r.Data.GAirmets {
+		line := fmt.Sprintf("%s %s %s +%dh valid %s", g.Product, g.Tag, g.Hazard.Type, g.ForecastHour, g.ValidTime.UTC().Format("2006-01-02T15:04:05Z"))
+		for _, a := range g.Altitude {
+			if len(a.LevelFtMSL) > 0 {
+				line += " " + a.LevelFtMSL
+			} else {
+				line += fmt.Sprintf(" %s-%s", a.MinFtMSL, a.MaxFtMSL)
+			}
+		}
+		if len(g.DueTo) > 0 {
+			line += " " + g.DueTo
+		}
+		s = append(s, line)
+	}
+	return
+}
+
+func (r *Response) ToJson() (s string, err error) {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
+
+func (r *Response) ToJsonIndented() (s string, err error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
@@ -0,0 +1,88 @@
+package gairmets
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func readResponse(t *testing.T) Response {
+	t.Helper()
+
+	b, err := ioutil.ReadFile("testdata/adds_gairmets.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Response
+	if err := xml.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestDecodeXML(t *testing.T) {
+	r := readResponse(t)
+	if r.Version != "1.3" || r.RequestIndex != 97311274 || r.DataSource.Name != "gairmets" || r.TimeTakenMs != 9 {
+		t.Errorf("response version/request index/data source/time = %s/%d/%s/%d",
+			r.Version, r.RequestIndex, r.DataSource.Name, r.TimeTakenMs)
+	}
+	if r.Data.NumResults != 3 || len(r.Data.GAirmets) != 3 {
+		t.Fatalf("got %d G-AIRMETs (num_results %d), want 3", len(r.Data.GAirmets), r.Data.NumResults)
+	}
+
+	ifr := r.Data.GAirmets[0]
+	if !ifr.IssueTime.Equal(time.Date(2026, 10, 18, 8, 45, 0, 0, time.UTC)) ||
+		!ifr.ValidTime.Equal(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)) ||
+		!ifr.ExpireTime.Equal(ifr.ValidTime) || ifr.ReceiptTime.IsZero() {
+		t.Errorf("issue/valid/expire/receipt time = %v/%v/%v/%v", ifr.IssueTime, ifr.ValidTime, ifr.ExpireTime, ifr.ReceiptTime)
+	}
+	if ifr.Product != "SIERRA" || ifr.Tag != "1W" || ifr.ForecastHour != 3 || ifr.Hazard.Type != "IFR" ||
+		ifr.GeometryType != "AREA" || ifr.DueTo != "CIG BLW 010/VIS BLW 3SM PCPN/BR" {
+		t.Errorf("IFR = %+v", ifr)
+	}
+	if ifr.Altitude != nil || ifr.FzlAltitude != nil {
+		t.Errorf("IFR altitude/fzl_altitude = %+v/%+v, want none", ifr.Altitude, ifr.FzlAltitude)
+	}
+	if ifr.Area.NumPoints != 4 || len(ifr.Area.Points) != 4 {
+		t.Errorf("IFR area has %d points (num_points %d), want 4", len(ifr.Area.Points), ifr.Area.NumPoints)
+	}
+
+	ice := r.Data.GAirmets[1]
+	if ice.Hazard.Type != "ICE" || ice.Hazard.Severity != "MOD" || len(ice.Altitude) != 1 {
+		t.Fatalf("ICE hazard/altitude = %+v/%+v", ice.Hazard, ice.Altitude)
+	}
+	if a := ice.Altitude[0]; a.MinFtMSL != "FZL" || a.MaxFtMSL != "16000" || a.LevelFtMSL != "" {
+		t.Errorf("ICE altitude = %+v, want FZL to 16000", a)
+	}
+	if ice.FzlAltitude == nil || ice.FzlAltitude.MinFtMSL != "4000" || ice.FzlAltitude.MaxFtMSL != "8000" {
+		t.Errorf("ICE fzl_altitude = %+v, want 4000 to 8000", ice.FzlAltitude)
+	}
+
+	fzlvl := r.Data.GAirmets[2]
+	if fzlvl.GeometryType != "LINE" || len(fzlvl.Altitude) != 1 || fzlvl.Altitude[0].LevelFtMSL != "4000" ||
+		fzlvl.ForecastHour != 0 || fzlvl.Area.NumPoints != 2 {
+		t.Errorf("FZLVL = %+v", fzlvl)
+	}
+}
+
+func TestSummaryAndFilter(t *testing.T) {
+	r := readResponse(t)
+
+	want := []string{
+		"SIERRA 1W IFR +3h valid 2026-10-18T12:00:00Z CIG BLW 010/VIS BLW 3SM PCPN/BR",
+		"ZULU 2C ICE +6h valid 2026-10-18T15:00:00Z FZL-16000",
+		"ZULU 3E FZLVL +0h valid 2026-10-18T09:00:00Z 4000",
+	}
+	if got := r.ToSummary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToSummary() = %q, want %q", got, want)
+	}
+
+	// a point just north of Portland, OR is inside the IFR area only
+	r.Filter(func(g *GAirmet) bool { return g.Contains(45.9, -122.6) })
+	if len(r.Data.GAirmets) != 1 || r.Data.NumResults != 1 || r.Data.GAirmets[0].Tag != "1W" {
+		t.Errorf("after Filter got %d (num_results %d), want the IFR G-AIRMET only", len(r.Data.GAirmets), r.Data.NumResults)
+	}
+}
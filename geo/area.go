@@ -0,0 +1,36 @@
+package geo
+
+import "encoding/xml"
+
+// AreaPoint is a point of an Area as the data server encodes it
+type AreaPoint struct {
+	XMLName   xml.Name `xml:"point" json:"-"`
+	Longitude float64  `xml:"longitude"`
+	Latitude  float64  `xml:"latitude"`
+}
+
+// Area is the polygon the data server gives for AIRMETs, SIGMETs and G-AIRMETs
+type Area struct {
+	XMLName   xml.Name    `xml:"area" json:"-"`
+	NumPoints int32       `xml:"num_points,attr"`
+	Points    []AreaPoint `xml:"point"`
+}
+
+// Polygon returns the points of the area as geo points
+func (a *Area) Polygon() []Point {
+	polygon := make([]Point, 0, len(a.Points))
+	for _, p := range a.Points {
+		polygon = append(polygon, Point{Lat: p.Latitude, Lon: p.Longitude})
+	}
+	return polygon
+}
+
+// Contains reports whether the point lies inside the area
+func (a *Area) Contains(lat, lon float64) bool {
+	return PolygonContains(a.Polygon(), Point{Lat: lat, Lon: lon})
+}
+
+// Intersects reports whether the area overlaps the bounding box
+func (a *Area) Intersects(minLat, minLon, maxLat, maxLon float64) bool {
+	return PolygonIntersectsBox(a.Polygon(), Box{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon})
+}
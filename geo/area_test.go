@@ -0,0 +1,50 @@
+package geo
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const testArea = `<area num_points="5">
+  <point><longitude>-100</longitude><latitude>40</latitude></point>
+  <point><longitude>-90</longitude><latitude>40</latitude></point>
+  <point><longitude>-90</longitude><latitude>45</latitude></point>
+  <point><longitude>-100</longitude><latitude>45</latitude></point>
+  <point><longitude>-100</longitude><latitude>40</latitude></point>
+</area>`
+
+func TestArea(t *testing.T) {
+	var a Area
+	if err := xml.Unmarshal([]byte(testArea), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.NumPoints != 5 || len(a.Points) != 5 {
+		t.Fatalf("decoded %d of %d points, want 5 of 5", len(a.Points), a.NumPoints)
+	}
+	if got := a.Polygon()[1]; got != (Point{Lat: 40, Lon: -90}) {
+		t.Errorf("Polygon()[1] = %v, want {40 -90}", got)
+	}
+
+	if !a.Contains(42, -95) {
+		t.Error("Contains(42, -95) = false, want true")
+	}
+	if a.Contains(46, -95) {
+		t.Error("Contains(46, -95) = true, want false")
+	}
+
+	tests := []struct {
+		name                           string
+		minLat, minLon, maxLat, maxLon float64
+		want                           bool
+	}{
+		{"box inside area", 41, -96, 42, -94, true},
+		{"area inside box", 30, -110, 50, -80, true},
+		{"edges cross", 35, -95, 50, -94, true},
+		{"disjoint", 30, -80, 35, -70, false},
+	}
+	for _, tt := range tests {
+		if got := a.Intersects(tt.minLat, tt.minLon, tt.maxLat, tt.maxLon); got != tt.want {
+			t.Errorf("%s: Intersects() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
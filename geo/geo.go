@@ -0,0 +1,125 @@
+package geo
+
+import "math"
+
+// Point is a location in decimal degrees
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Box is a latitude/longitude bounding box in decimal degrees
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+func (b Box) Contains(p Point) bool {
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+}
+
+// PolygonContains reports whether p lies inside polygon using the even-odd rule. The polygon may be given closed
+// (last point equal to the first) or open.
+func PolygonContains(polygon []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// PolygonIntersectsBox reports whether polygon and b share at least one point
+func PolygonIntersectsBox(polygon []Point, b Box) bool {
+	if len(polygon) == 0 {
+		return false
+	}
+
+	for _, p := range polygon {
+		if b.Contains(p) {
+			return true
+		}
+	}
+
+	corners := []Point{
+		{b.MinLat, b.MinLon},
+		{b.MinLat, b.MaxLon},
+		{b.MaxLat, b.MaxLon},
+		{b.MaxLat, b.MinLon},
+	}
+	for _, c := range corners {
+		if PolygonContains(polygon, c) {
+			return true
+		}
+	}
+
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		for k := range corners {
+			if segmentsIntersect(polygon[j], polygon[i], corners[k], corners[(k+1)%len(corners)]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// segmentsIntersect reports whether segment p1-p2 intersects segment p3-p4
+func segmentsIntersect(p1, p2, p3, p4 Point) bool {
+	d1 := direction(p3, p4, p1)
+	d2 := direction(p3, p4, p2)
+	d3 := direction(p1, p2, p3)
+	d4 := direction(p1, p2, p4)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(p3, p4, p1)) ||
+		(d2 == 0 && onSegment(p3, p4, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, p3)) ||
+		(d4 == 0 && onSegment(p1, p2, p4))
+}
+
+// direction returns the cross product of (c - a) and (b - a)
+func direction(a, b, c Point) float64 {
+	return (c.Lon-a.Lon)*(b.Lat-a.Lat) - (b.Lon-a.Lon)*(c.Lat-a.Lat)
+}
+
+// onSegment reports whether c, known to be collinear with a-b, lies within the segment's bounding box
+func onSegment(a, b, c Point) bool {
+	return c.Lon >= min(a.Lon, b.Lon) && c.Lon <= max(a.Lon, b.Lon) &&
+		c.Lat >= min(a.Lat, b.Lat) && c.Lat <= max(a.Lat, b.Lat)
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// EarthRadiusSM is the mean radius of the Earth in statute miles
+const EarthRadiusSM = 3958.8
+
+// DistanceSM returns the great-circle distance between a and b in statute miles
+func DistanceSM(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusSM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
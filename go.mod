@@ -2,9 +2,9 @@ module github.com/theperiscope/avwx
 
 go 1.17
 
-require github.com/spf13/cobra v1.2.1
-
 require (
-	github.com/inconshreveable/mousetrap v1.0.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
 )
+
+require github.com/inconshreveable/mousetrap v1.0.0 // indirect
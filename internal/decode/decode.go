@@ -0,0 +1,183 @@
+// Package decode decodes the groups METARs and TAFs have in common
+package decode
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theperiscope/avwx/wx"
+)
+
+var (
+	windPattern       = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	windRangePattern  = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	wholeMilesPattern = regexp.MustCompile(`^\d$`)
+	milesPattern      = regexp.MustCompile(`^([PM])?(\d+)(?:/(\d+))?SM$`)
+	metersPattern     = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	skyPattern        = regexp.MustCompile(`^(FEW|SCT|BKN|OVC)(\d{3})(CB|TCU|///)?$`)
+	vertVisPattern    = regexp.MustCompile(`^VV(\d{3})$`)
+	missingPattern    = regexp.MustCompile(`^/+[A-Z]*$`)
+)
+
+// Wind is a decoded wind group, with speeds converted to knots. Direction is 0 for variable wind, as reported by ADDS.
+type Wind struct {
+	DirDegrees int32
+	SpeedKt    int32
+	GustKt     int32
+}
+
+// ParseWind decodes wind groups like 27015G25KT, VRB03KT or 24012MPS
+func ParseWind(g string) (Wind, bool) {
+	s := windPattern.FindStringSubmatch(g)
+	if s == nil {
+		return Wind{}, false
+	}
+
+	factor := 1.0
+	switch s[4] {
+	case "MPS":
+		factor = 1.943844
+	case "KMH":
+		factor = 1 / 1.852
+	}
+
+	var w Wind
+	if s[1] != "VRB" {
+		w.DirDegrees = Atoi(s[1])
+	}
+	w.SpeedKt = int32(math.Round(float64(Atoi(s[2])) * factor))
+	if len(s[3]) > 0 {
+		w.GustKt = int32(math.Round(float64(Atoi(s[3])) * factor))
+	}
+	return w, true
+}
+
+// ParseWindRange decodes variable wind direction groups like 210V280
+func ParseWindRange(g string) (from, to int32, ok bool) {
+	s := windRangePattern.FindStringSubmatch(g)
+	if s == nil {
+		return 0, 0, false
+	}
+	return Atoi(s[1]), Atoi(s[2]), true
+}
+
+// ParseVisibility decodes the visibility starting at groups[i] in statute miles and returns the number of groups it
+// used, 2 for whole miles followed by a fraction like 1 1/2SM. As ADDS does, M1/4SM is decoded as 0.25, P6SM as 6,
+// CAVOK as 10 and 9999 meters as 10 km.
+func ParseVisibility(groups []string, i int) (mi float64, n int, ok bool) {
+	g := groups[i]
+	switch {
+	case g == "CAVOK":
+		return 10, 1, true
+	case wholeMilesPattern.MatchString(g) && i+1 < len(groups) && milesPattern.MatchString(groups[i+1]):
+		return float64(Atoi(g)) + miles(milesPattern.FindStringSubmatch(groups[i+1])), 2, true
+	case milesPattern.MatchString(g):
+		return miles(milesPattern.FindStringSubmatch(g)), 1, true
+	case metersPattern.MatchString(g):
+		meters := Atoi(metersPattern.FindStringSubmatch(g)[1])
+		if meters == 9999 {
+			meters = 10000
+		}
+		return Round(float64(meters)/1609.344, 2), 1, true
+	}
+	return 0, 0, false
+}
+
+func miles(s []string) float64 {
+	if len(s[3]) > 0 {
+		return Round(float64(Atoi(s[2]))/float64(Atoi(s[3])), 2)
+	}
+	return float64(Atoi(s[2]))
+}
+
+// Sky is a decoded sky condition group
+type Sky struct {
+	Cover     string // SKC, CLR, NSC, NCD, FEW, SCT, BKN or OVC
+	BaseFtAGL int32
+	CloudType string // CB, TCU or empty
+}
+
+// ParseSky decodes sky condition groups like BKN025CB or SKC. Automated stations that cannot tell the cloud type
+// report it as ///, like BKN025///, which is decoded as no cloud type.
+func ParseSky(g string) (Sky, bool) {
+	switch g {
+	case "SKC", "CLR", "NSC", "NCD":
+		return Sky{Cover: g}, true
+	}
+
+	s := skyPattern.FindStringSubmatch(g)
+	if s == nil {
+		return Sky{}, false
+	}
+	sky := Sky{Cover: s[1], BaseFtAGL: Atoi(s[2]) * 100}
+	if s[3] != "///" {
+		sky.CloudType = s[3]
+	}
+	return sky, true
+}
+
+// ParseVertVis decodes vertical visibility groups like VV002 in feet
+func ParseVertVis(g string) (int32, bool) {
+	s := vertVisPattern.FindStringSubmatch(g)
+	if s == nil {
+		return 0, false
+	}
+	return Atoi(s[1]) * 100, true
+}
+
+// IsWeather reports whether g is a present weather group like -SHRA, +TSRAGR or VCFG
+func IsWeather(g string) bool {
+	_, err := wx.ParseGroup(g)
+	return err == nil && g != "NSW"
+}
+
+// IsRecentWeather reports whether g is a recent weather group like RERA
+func IsRecentWeather(g string) bool {
+	return strings.HasPrefix(g, "RE") && IsWeather(g[2:])
+}
+
+// IsMissing reports whether g is a group an automated station could not report, like ///// or //////CB
+func IsMissing(g string) bool {
+	return missingPattern.MatchString(g)
+}
+
+// DayTime returns the time at day, hour and minute of the month of ref or, if that is more than a day after ref or the
+// month has no such day, of the latest month before that has it. Reports only give the day of the month. Hour 24 is
+// the midnight ending day.
+func DayTime(day, hour, minute int, ref time.Time) time.Time {
+	ref = ref.UTC()
+	if day <= daysIn(ref.Year(), ref.Month()) {
+		t := time.Date(ref.Year(), ref.Month(), day, hour, minute, 0, 0, time.UTC)
+		if t.Sub(ref) <= 24*time.Hour {
+			return t
+		}
+	}
+
+	// a day of 31 at the latest is found two months back, the limit only stops days no month has
+	for n := 1; n <= 12; n++ {
+		month := time.Date(ref.Year(), ref.Month()-time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+		if day <= daysIn(month.Year(), month.Month()) {
+			return time.Date(month.Year(), month.Month(), day, hour, minute, 0, 0, time.UTC)
+		}
+	}
+	return time.Date(ref.Year(), ref.Month(), day, hour, minute, 0, 0, time.UTC)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Atoi converts a string of digits matched by a pattern, it returns 0 for anything else
+func Atoi(s string) int32 {
+	i, _ := strconv.Atoi(s)
+	return int32(i)
+}
+
+// Round rounds v to decimals decimal places
+func Round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
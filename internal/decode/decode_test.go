@@ -0,0 +1,79 @@
+package decode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDayTime(t *testing.T) {
+	ref := func(s string) time.Time {
+		r, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name              string
+		day, hour, minute int
+		ref               string
+		want              string
+	}{
+		{"same day", 18, 11, 51, "2026-10-18T12:00:00Z", "2026-10-18T11:51:00Z"},
+		{"up to a day ahead of a slow clock", 19, 11, 0, "2026-10-18T12:00:00Z", "2026-10-19T11:00:00Z"},
+		{"previous month", 30, 23, 55, "2026-10-01T00:10:00Z", "2026-09-30T23:55:00Z"},
+		{"31st skips a 30 day month", 31, 11, 50, "2026-03-01T12:00:00Z", "2026-01-31T11:50:00Z"},
+		{"31st of the previous month", 31, 11, 50, "2026-04-01T12:00:00Z", "2026-03-31T11:50:00Z"},
+		{"31st skips April", 31, 11, 50, "2026-05-01T00:00:00Z", "2026-03-31T11:50:00Z"},
+		{"29th outside a leap year", 29, 6, 0, "2026-03-01T12:00:00Z", "2026-01-29T06:00:00Z"},
+		{"29th of a leap year February", 29, 6, 0, "2028-03-01T12:00:00Z", "2028-02-29T06:00:00Z"},
+		{"30th skips February", 30, 18, 0, "2026-03-02T12:00:00Z", "2026-01-30T18:00:00Z"},
+		{"across the year", 31, 23, 0, "2027-01-01T01:00:00Z", "2026-12-31T23:00:00Z"},
+		{"hour 24", 17, 24, 0, "2026-10-18T12:00:00Z", "2026-10-18T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DayTime(tt.day, tt.hour, tt.minute, ref(tt.ref))
+			if want := ref(tt.want); !got.Equal(want) {
+				t.Errorf("DayTime(%d, %d, %d, %s) = %v, want %v", tt.day, tt.hour, tt.minute, tt.ref, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSky(t *testing.T) {
+	tests := []struct {
+		group string
+		want  Sky
+		ok    bool
+	}{
+		{"SKC", Sky{Cover: "SKC"}, true},
+		{"FEW250", Sky{Cover: "FEW", BaseFtAGL: 25000}, true},
+		{"BKN025CB", Sky{Cover: "BKN", BaseFtAGL: 2500, CloudType: "CB"}, true},
+		{"SCT040TCU", Sky{Cover: "SCT", BaseFtAGL: 4000, CloudType: "TCU"}, true},
+		{"BKN025///", Sky{Cover: "BKN", BaseFtAGL: 2500}, true},
+		{"OVC010///", Sky{Cover: "OVC", BaseFtAGL: 1000}, true},
+		{"//////CB", Sky{}, false},
+		{"BKN25", Sky{}, false},
+		{"OVC010//", Sky{}, false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := ParseSky(tt.group); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseSky(%q) = %+v, %v, want %+v, %v", tt.group, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIsWeather(t *testing.T) {
+	for g, want := range map[string]bool{
+		"-SHRA": true, "TS": true, "VCSH": true, "VCFG": true, "FZFG": true,
+		"NSW": false, "-FZ": false, "FZ": false, "BL": false, "SH": false, "10SM": false,
+	} {
+		if got := IsWeather(g); got != want {
+			t.Errorf("IsWeather(%q) = %v, want %v", g, got, want)
+		}
+	}
+}
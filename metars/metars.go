@@ -1,9 +1,14 @@
 package metars
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"strconv"
 	"time"
+
+	"github.com/theperiscope/avwx/wx"
 )
 
 type Response struct {
@@ -57,7 +62,10 @@ type Metar struct {
 	WindDirDegrees            int32               `xml:"wind_dir_degrees"`
 	WindSpeedKt               int32               `xml:"wind_speed_kt"`
 	WindGustKt                int32               `xml:"wind_gust_kt"`
+	WindDirFromDegrees        int32               `xml:"-" json:",omitempty"` // variable wind direction range, set by Parse
+	WindDirToDegrees          int32               `xml:"-" json:",omitempty"`
 	VisibilityStatuteMi       float64             `xml:"visibility_statute_mi"`
+	RunwayVisualRange         []RunwayVisualRange `xml:"-" json:",omitempty"` // set by Parse
 	AltimInHg                 float64             `xml:"altim_in_hg"`
 	SeaLevelPressureMb        float64             `xml:"sea_level_pressure_mb"`
 	QualityControlFlags       QualityControlFlags `xml:"quality_control_flags"`
@@ -77,6 +85,7 @@ type Metar struct {
 	VertVisFt                 int32               `xml:"vert_vis_ft"`
 	MetarType                 string              `xml:"metar_type"`
 	ElevationM                float64             `xml:"elevation_m"`
+	Remarks                   *Remarks            `xml:"-" json:",omitempty"` // set by DecodeRemarks
 }
 
 func (r *Response) ToRawTextOnly() (s []string) {
@@ -105,3 +114,60 @@ func (r *Response) ToJsonIndented() (s string, err error) {
 	s = string(bytes)
 	return
 }
+
+// csvSkyConditions is the number of sky condition column pairs written by ToCsv
+const csvSkyConditions = 4
+
+// CsvHeader returns the columns written by ToCsv, in order
+func CsvHeader() []string {
+	h := []string{"station_id", "observation_time", "latitude", "longitude", "elevation_m", "temp_c", "dewpoint_c",
+		"wind_dir_degrees", "wind_speed_kt", "wind_gust_kt", "visibility_statute_mi", "altim_in_hg",
+		"sea_level_pressure_mb", "wx_string"}
+	for i := 1; i <= csvSkyConditions; i++ {
+		h = append(h, "sky_cover_"+strconv.Itoa(i), "cloud_base_ft_agl_"+strconv.Itoa(i))
+	}
+	return append(h, "vert_vis_ft", "flight_category", "metar_type", "auto_station", "raw_text")
+}
+
+// CsvRecord returns the values of the metar in the order of CsvHeader
+func (m *Metar) CsvRecord() []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	i := func(v int32) string { return strconv.FormatInt(int64(v), 10) }
+
+	rec := []string{m.StationId, m.ObservationTime.UTC().Format(time.RFC3339), f(m.Latitude), f(m.Longitude),
+		f(m.ElevationM), f(m.TempC), f(m.DewpointC), i(m.WindDirDegrees), i(m.WindSpeedKt), i(m.WindGustKt),
+		f(m.VisibilityStatuteMi), f(m.AltimInHg), f(m.SeaLevelPressureMb), m.WxString}
+	for n := 0; n < csvSkyConditions; n++ {
+		if n < len(m.SkyCondition) {
+			rec = append(rec, m.SkyCondition[n].SkyCover, i(m.SkyCondition[n].CloudBaseFtAGL))
+		} else {
+			rec = append(rec, "", "")
+		}
+	}
+	return append(rec, i(m.VertVisFt), m.FlightCategory, m.MetarType,
+		strconv.FormatBool(m.QualityControlFlags.AutoStation), m.RawText)
+}
+
+// ToCsv returns the METARs as CSV with a header row and one row per METAR
+func (r *Response) ToCsv() (s string, err error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	w.Write(CsvHeader())
+	for i := range r.Data.Metars {
+		w.Write(r.Data.Metars[i].CsvRecord())
+	}
+
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return "", err
+	}
+
+	s = b.String()
+	return
+}
+
+// WeatherPhenomena decodes WxString
+func (m *Metar) WeatherPhenomena() ([]wx.Phenomenon, error) {
+	return wx.Parse(m.WxString)
+}
@@ -0,0 +1,222 @@
+package metars
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/theperiscope/avwx/internal/decode"
+)
+
+// RunwayVisualRange is an RVR group of a METAR. Visibilities are in feet, or meters when Unit is "M".
+type RunwayVisualRange struct {
+	Runway        string
+	VisibilityMin int32
+	VisibilityMax int32  `json:",omitempty"` // 0 unless the visibility varies
+	MinModifier   string `json:",omitempty"` // P (more than) or M (less than) VisibilityMin
+	MaxModifier   string `json:",omitempty"` // P (more than) or M (less than) VisibilityMax
+	Unit          string
+	Tendency      string `json:",omitempty"` // U (up), D (down) or N (no change)
+}
+
+// ParseError is returned by Parse, together with the decoded METAR, for groups it did not recognize
+type ParseError struct {
+	RawText string
+	Groups  []string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("unrecognized group(s) %s in METAR %q", strings.Join(e.Groups, " "), e.RawText)
+}
+
+var (
+	stationPattern     = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	timePattern        = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	rvrPattern         = regexp.MustCompile(`^R(\d{2}[LCR]?)/([PM])?(\d{4})(?:V([PM])?(\d{4}))?(FT)?/?([UDN])?$`)
+	temperaturePattern = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	altimeterPattern   = regexp.MustCompile(`^([AQ])(\d{4})$`)
+)
+
+// Parse decodes a raw METAR or SPECI report into a Metar, with the observation time taken to be in the current month.
+// See ParseAt.
+func Parse(raw string) (*Metar, error) {
+	return ParseAt(raw, time.Now())
+}
+
+// ParseAt decodes a raw METAR or SPECI report into a Metar. The report only gives the day of the month of the
+// observation, which is placed in the month of ref or, if that would be more than a day after ref or the month has no
+// such day, the latest month before that has it.
+//
+// Remarks and trend forecasts are not decoded. Groups that are not recognized are returned in a *ParseError together
+// with the METAR decoded from the other groups; a report without station or observation time is an error.
+func ParseAt(raw string, ref time.Time) (*Metar, error) {
+	m := &Metar{RawText: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "="))}
+	groups := strings.Fields(strings.ToUpper(m.RawText))
+
+	if len(groups) > 0 && (groups[0] == "METAR" || groups[0] == "SPECI") {
+		m.MetarType = groups[0]
+		groups = groups[1:]
+	} else {
+		m.MetarType = "METAR"
+	}
+
+	if len(groups) == 0 || !stationPattern.MatchString(groups[0]) {
+		return nil, fmt.Errorf("METAR %q does not start with a station identifier", raw)
+	}
+	m.StationId = groups[0]
+	groups = groups[1:]
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("METAR %q has no observation time", raw)
+	}
+	t, ok := parseDayTime(groups[0], ref)
+	if !ok {
+		return nil, fmt.Errorf("METAR %q has no valid observation time", raw)
+	}
+	m.ObservationTime = t
+	groups = groups[1:]
+
+	var unknown []string
+	var weather []string
+	hasVisibility := false
+
+	for i := 0; i < len(groups); i++ {
+		g := groups[i]
+
+		if g == "RMK" || g == "NOSIG" || g == "BECMG" || g == "TEMPO" {
+			break
+		}
+
+		if w, ok := decode.ParseWind(g); ok {
+			m.WindDirDegrees, m.WindSpeedKt, m.WindGustKt = w.DirDegrees, w.SpeedKt, w.GustKt
+			continue
+		}
+		if from, to, ok := decode.ParseWindRange(g); ok {
+			m.WindDirFromDegrees, m.WindDirToDegrees = from, to
+			continue
+		}
+		if mi, n, ok := decode.ParseVisibility(groups, i); ok {
+			m.VisibilityStatuteMi = mi
+			hasVisibility = true
+			i += n - 1
+			continue
+		}
+		if sky, ok := decode.ParseSky(g); ok {
+			m.SkyCondition = append(m.SkyCondition, SkyCondition{SkyCover: sky.Cover, CloudBaseFtAGL: sky.BaseFtAGL})
+			continue
+		}
+		if vv, ok := decode.ParseVertVis(g); ok {
+			// ADDS reports an indefinite ceiling as an obscured sky with the vertical visibility
+			m.VertVisFt = vv
+			m.SkyCondition = append(m.SkyCondition, SkyCondition{SkyCover: "OVX"})
+			continue
+		}
+
+		switch {
+		case g == "AUTO":
+			m.QualityControlFlags.AutoStation = true
+		case g == "COR" || g == "NIL" || g == "NSW":
+		case rvrPattern.MatchString(g):
+			m.RunwayVisualRange = append(m.RunwayVisualRange, parseRVR(rvrPattern.FindStringSubmatch(g)))
+		case temperaturePattern.MatchString(g):
+			s := temperaturePattern.FindStringSubmatch(g)
+			m.TempC = float64(signedTemperature(s[1]))
+			if len(s[2]) > 0 {
+				m.DewpointC = float64(signedTemperature(s[2]))
+			}
+		case altimeterPattern.MatchString(g):
+			s := altimeterPattern.FindStringSubmatch(g)
+			if s[1] == "A" {
+				m.AltimInHg = float64(decode.Atoi(s[2])) / 100
+			} else {
+				m.AltimInHg = decode.Round(float64(decode.Atoi(s[2]))*hPaToInHg, 2)
+			}
+		case decode.IsWeather(g):
+			weather = append(weather, g)
+		case decode.IsRecentWeather(g) || decode.IsMissing(g):
+		default:
+			unknown = append(unknown, g)
+		}
+	}
+
+	m.WxString = strings.Join(weather, " ")
+	m.FlightCategory = flightCategory(m, hasVisibility)
+
+	if len(unknown) > 0 {
+		return m, &ParseError{RawText: m.RawText, Groups: unknown}
+	}
+	return m, nil
+}
+
+// hPaToInHg converts hectopascals to inches of mercury
+const hPaToInHg = 0.0295299830714
+
+// parseDayTime decodes a DDHHMMZ group, see decode.DayTime
+func parseDayTime(g string, ref time.Time) (time.Time, bool) {
+	s := timePattern.FindStringSubmatch(g)
+	if s == nil {
+		return time.Time{}, false
+	}
+
+	day, hour, minute := int(decode.Atoi(s[1])), int(decode.Atoi(s[2])), int(decode.Atoi(s[3]))
+	if day < 1 || day > 31 || hour > 24 || minute > 59 {
+		return time.Time{}, false
+	}
+	return decode.DayTime(day, hour, minute, ref), true
+}
+
+func parseRVR(s []string) RunwayVisualRange {
+	r := RunwayVisualRange{
+		Runway:        s[1],
+		MinModifier:   s[2],
+		VisibilityMin: decode.Atoi(s[3]),
+		Tendency:      s[7],
+		Unit:          "M",
+	}
+	if len(s[5]) > 0 {
+		r.VisibilityMax = decode.Atoi(s[5])
+		r.MaxModifier = s[4]
+	}
+	if s[6] == "FT" {
+		r.Unit = "FT"
+	}
+	return r
+}
+
+func signedTemperature(s string) int32 {
+	if strings.HasPrefix(s, "M") {
+		return -decode.Atoi(s[1:])
+	}
+	return decode.Atoi(s)
+}
+
+// flightCategory classifies the METAR the way ADDS does, from the ceiling and the visibility
+func flightCategory(m *Metar, hasVisibility bool) string {
+	ceiling := int32(-1)
+	for _, s := range m.SkyCondition {
+		if s.SkyCover == "BKN" || s.SkyCover == "OVC" {
+			ceiling = s.CloudBaseFtAGL
+			break
+		}
+		if s.SkyCover == "OVX" {
+			ceiling = m.VertVisFt
+			break
+		}
+	}
+	if ceiling < 0 && !hasVisibility {
+		return ""
+	}
+
+	hasCeiling := ceiling >= 0
+	vis := m.VisibilityStatuteMi
+	switch {
+	case hasCeiling && ceiling < 500 || hasVisibility && vis < 1:
+		return "LIFR"
+	case hasCeiling && ceiling < 1000 || hasVisibility && vis < 3:
+		return "IFR"
+	case hasCeiling && ceiling <= 3000 || hasVisibility && vis <= 5:
+		return "MVFR"
+	}
+	return "VFR"
+}
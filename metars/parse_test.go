@@ -0,0 +1,201 @@
+package metars
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testRef = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw   string
+		check func(t *testing.T, m *Metar)
+	}{
+		{"KORD 181151Z 27015G24KT 10SM FEW250 12/M03 A3011 RMK AO2 SLP194", func(t *testing.T, m *Metar) {
+			if m.MetarType != "METAR" || m.StationId != "KORD" || !m.ObservationTime.Equal(time.Date(2026, 10, 18, 11, 51, 0, 0, time.UTC)) {
+				t.Errorf("type/station/time = %s/%s/%v", m.MetarType, m.StationId, m.ObservationTime)
+			}
+			if m.WindDirDegrees != 270 || m.WindSpeedKt != 15 || m.WindGustKt != 24 {
+				t.Errorf("wind = %d@%dG%d, want 270@15G24", m.WindDirDegrees, m.WindSpeedKt, m.WindGustKt)
+			}
+			if m.VisibilityStatuteMi != 10 || m.TempC != 12 || m.DewpointC != -3 || m.AltimInHg != 30.11 {
+				t.Errorf("visibility/temp/dewpoint/altimeter = %v/%v/%v/%v", m.VisibilityStatuteMi, m.TempC, m.DewpointC, m.AltimInHg)
+			}
+			if !reflect.DeepEqual(m.SkyCondition, []SkyCondition{{SkyCover: "FEW", CloudBaseFtAGL: 25000}}) {
+				t.Errorf("sky = %+v", m.SkyCondition)
+			}
+			if m.FlightCategory != "VFR" {
+				t.Errorf("flight category = %s, want VFR", m.FlightCategory)
+			}
+		}},
+		{"SPECI KDEN 181205Z 24012KT 210V290 6SM -SHRA BKN025 OVC080 08/02 A3002", func(t *testing.T, m *Metar) {
+			if m.MetarType != "SPECI" || m.WindDirFromDegrees != 210 || m.WindDirToDegrees != 290 {
+				t.Errorf("type/variable wind = %s/%d-%d, want SPECI/210-290", m.MetarType, m.WindDirFromDegrees, m.WindDirToDegrees)
+			}
+			if m.WxString != "-SHRA" || m.FlightCategory != "MVFR" {
+				t.Errorf("weather/flight category = %s/%s, want -SHRA/MVFR", m.WxString, m.FlightCategory)
+			}
+		}},
+		{"KBOS 181154Z VRB03KT 1 1/2SM BR OVC008 09/08 A2998", func(t *testing.T, m *Metar) {
+			if m.WindDirDegrees != 0 || m.WindSpeedKt != 3 {
+				t.Errorf("variable wind = %d@%d, want 0@3", m.WindDirDegrees, m.WindSpeedKt)
+			}
+			if m.VisibilityStatuteMi != 1.5 || m.FlightCategory != "IFR" {
+				t.Errorf("visibility/flight category = %v/%s, want 1.5/IFR", m.VisibilityStatuteMi, m.FlightCategory)
+			}
+		}},
+		{"KSFO 181156Z AUTO 00000KT M1/4SM FG VV002 14/13 A2992 RMK AO2", func(t *testing.T, m *Metar) {
+			if !m.QualityControlFlags.AutoStation || m.WindSpeedKt != 0 {
+				t.Errorf("auto/wind = %v/%d", m.QualityControlFlags.AutoStation, m.WindSpeedKt)
+			}
+			if m.VisibilityStatuteMi != 0.25 {
+				t.Errorf("visibility M1/4SM = %v, want 0.25", m.VisibilityStatuteMi)
+			}
+			if m.VertVisFt != 200 || !reflect.DeepEqual(m.SkyCondition, []SkyCondition{{SkyCover: "OVX"}}) {
+				t.Errorf("vertical visibility/sky = %d/%+v, want 200 and an obscured sky", m.VertVisFt, m.SkyCondition)
+			}
+			if m.FlightCategory != "LIFR" {
+				t.Errorf("flight category = %s, want LIFR", m.FlightCategory)
+			}
+		}},
+		{"EGLL 181150Z COR 05008KT 0800 R27L/0600V1000FT/U R09R/M0050 FG OVC002 M01/M02 Q1013", func(t *testing.T, m *Metar) {
+			want := []RunwayVisualRange{
+				{Runway: "27L", VisibilityMin: 600, VisibilityMax: 1000, Unit: "FT", Tendency: "U"},
+				{Runway: "09R", VisibilityMin: 50, MinModifier: "M", Unit: "M"},
+			}
+			if !reflect.DeepEqual(m.RunwayVisualRange, want) {
+				t.Errorf("RVR = %+v, want %+v", m.RunwayVisualRange, want)
+			}
+			if m.TempC != -1 || m.DewpointC != -2 {
+				t.Errorf("temp/dewpoint M01/M02 = %v/%v, want -1/-2", m.TempC, m.DewpointC)
+			}
+			if m.AltimInHg != 29.91 {
+				t.Errorf("altimeter Q1013 = %v, want 29.91 inHg", m.AltimInHg)
+			}
+			if m.VisibilityStatuteMi != 0.5 {
+				t.Errorf("visibility 0800 m = %v, want 0.5", m.VisibilityStatuteMi)
+			}
+		}},
+		{"KXYZ 181150Z NIL", func(t *testing.T, m *Metar) {
+			if m.StationId != "KXYZ" || m.WindSpeedKt != 0 || m.VisibilityStatuteMi != 0 || len(m.SkyCondition) != 0 {
+				t.Errorf("NIL METAR = %+v", m)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			m, err := ParseAt(tt.raw, testRef)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, m)
+		})
+	}
+}
+
+// TestParseCorpus decodes reports as stations around the world send them
+func TestParseCorpus(t *testing.T) {
+	tests := []struct {
+		raw        string
+		wind       [3]int32 // direction, speed and gust in knots
+		visibility float64
+		sky        []SkyCondition
+		wx         string
+		category   string
+	}{
+		{"KDEN 181153Z 36008KT P6SM SCT100 BKN250 12/M02 A3010 RMK AO2 SLP153",
+			[3]int32{360, 8, 0}, 6, []SkyCondition{{SkyCover: "SCT", CloudBaseFtAGL: 10000}, {SkyCover: "BKN", CloudBaseFtAGL: 25000}}, "", "VFR"},
+		{"KMIA 181153Z 09012G20KT 10SM VCSH FEW020 SCT045 28/23 A3001",
+			[3]int32{90, 12, 20}, 10, []SkyCondition{{SkyCover: "FEW", CloudBaseFtAGL: 2000}, {SkyCover: "SCT", CloudBaseFtAGL: 4500}}, "VCSH", "VFR"},
+		{"KBUF 181154Z 27020G32KT 1/4SM +SN FZFG VV005 M04/M05 A2980",
+			[3]int32{270, 20, 32}, 0.25, []SkyCondition{{SkyCover: "OVX"}}, "+SN FZFG", "LIFR"},
+		{"CYYZ 181200Z 27010KT 15SM BKN025 OVC100 08/03 A3002 RMK SC5AC2 SLP171",
+			[3]int32{270, 10, 0}, 15, []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 2500}, {SkyCover: "OVC", CloudBaseFtAGL: 10000}}, "", "MVFR"},
+		{"METAR EDDF 181150Z 27012KT 240V300 CAVOK 14/06 Q1021 NOSIG",
+			[3]int32{270, 12, 0}, 10, nil, "", "VFR"},
+		{"UUEE 181200Z 24005MPS 9999 -SHRA BKN016CB OVC050 09/07 Q1008 NOSIG",
+			[3]int32{240, 10, 0}, 6.21, []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 1600}, {SkyCover: "OVC", CloudBaseFtAGL: 5000}}, "-SHRA", "MVFR"},
+		{"ZBAA 181200Z 02004MPS 3500 BR NSC 12/09 Q1019 NOSIG",
+			[3]int32{20, 8, 0}, 2.17, []SkyCondition{{SkyCover: "NSC"}}, "BR", "IFR"},
+		{"ZMUB 181200Z 33036G54KMH 9999 SCT030 M02/M11 Q1025 NOSIG",
+			[3]int32{330, 19, 29}, 6.21, []SkyCondition{{SkyCover: "SCT", CloudBaseFtAGL: 3000}}, "", "VFR"},
+		{"EGLL 181150Z 24015G27KT 9999 -RA FEW012 BKN025 09/07 Q0998 RERA NOSIG",
+			[3]int32{240, 15, 27}, 6.21, []SkyCondition{{SkyCover: "FEW", CloudBaseFtAGL: 1200}, {SkyCover: "BKN", CloudBaseFtAGL: 2500}}, "-RA", "MVFR"},
+		{"LFPG 181200Z AUTO 22008KT 9999NDV BKN033/// OVC041/// 13/10 Q1014 RETSRA NOSIG",
+			[3]int32{220, 8, 0}, 6.21, []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 3300}, {SkyCover: "OVC", CloudBaseFtAGL: 4100}}, "", "VFR"},
+		{"EGPD 181150Z AUTO 23012KT 9999NDV -RA BKN025/// OVC010/// //////CB 11/09 Q1003",
+			[3]int32{230, 12, 0}, 6.21, []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 2500}, {SkyCover: "OVC", CloudBaseFtAGL: 1000}}, "-RA", "MVFR"},
+		{"EKCH 181150Z AUTO /////KT //// // ////// 12/08 Q1012",
+			[3]int32{0, 0, 0}, 0, nil, "", ""},
+		{"RJTT 181200Z 35008KT 9999 FEW030 20/12 Q1020 NOSIG",
+			[3]int32{350, 8, 0}, 6.21, []SkyCondition{{SkyCover: "FEW", CloudBaseFtAGL: 3000}}, "", "VFR"},
+		{"KLAX 181153Z 00000KT 1/2SM FG VV001 16/16 A2994",
+			[3]int32{0, 0, 0}, 0.5, []SkyCondition{{SkyCover: "OVX"}}, "FG", "LIFR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			m, err := ParseAt(tt.raw, testRef)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wind := [3]int32{m.WindDirDegrees, m.WindSpeedKt, m.WindGustKt}; wind != tt.wind {
+				t.Errorf("wind = %v, want %v", wind, tt.wind)
+			}
+			if m.VisibilityStatuteMi != tt.visibility {
+				t.Errorf("visibility = %v, want %v", m.VisibilityStatuteMi, tt.visibility)
+			}
+			if !reflect.DeepEqual(m.SkyCondition, tt.sky) {
+				t.Errorf("sky = %+v, want %+v", m.SkyCondition, tt.sky)
+			}
+			if m.WxString != tt.wx || m.FlightCategory != tt.category {
+				t.Errorf("weather/flight category = %q/%q, want %q/%q", m.WxString, m.FlightCategory, tt.wx, tt.category)
+			}
+		})
+	}
+}
+
+func TestParseObservationMonth(t *testing.T) {
+	tests := []struct {
+		raw  string
+		ref  time.Time
+		want time.Time
+	}{
+		{"KABC 311150Z 00000KT 10SM CLR 10/05 A3000", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 31, 11, 50, 0, 0, time.UTC)},
+		{"KABC 291150Z 00000KT 10SM CLR 10/05 A3000", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 29, 11, 50, 0, 0, time.UTC)},
+		{"KABC 291150Z 00000KT 10SM CLR 10/05 A3000", time.Date(2028, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 11, 50, 0, 0, time.UTC)},
+		{"KABC 301150Z 00000KT 10SM CLR 10/05 A3000", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 30, 11, 50, 0, 0, time.UTC)},
+		{"KABC 311150Z 00000KT 10SM CLR 10/05 A3000", time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 31, 11, 50, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		m, err := ParseAt(tt.raw, tt.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !m.ObservationTime.Equal(tt.want) {
+			t.Errorf("ParseAt(%q, %v) observation time = %v, want %v", tt.raw, tt.ref, m.ObservationTime, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	m, err := ParseAt("KORD 181151Z 27015KT 10SM XYZZY CLR 12/M03 A3011", testRef)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !reflect.DeepEqual(parseErr.Groups, []string{"XYZZY"}) {
+		t.Fatalf("err = %v, want a ParseError for XYZZY", err)
+	}
+	if m == nil || m.AltimInHg != 30.11 {
+		t.Errorf("METAR = %+v, want the other groups decoded", m)
+	}
+
+	for _, raw := range []string{"", "181151Z 27015KT", "KORD", "KORD 27015KT", "KORD 321151Z 27015KT"} {
+		if _, err := ParseAt(raw, testRef); err == nil {
+			t.Errorf("ParseAt(%q) succeeded, want an error", raw)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package metars
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/theperiscope/avwx/internal/decode"
+)
+
+// Remarks holds the groups of a METAR's RMK section that DecodeRemarks understands. Temperatures are in °C and
+// times are resolved relative to the observation time.
+type Remarks struct {
+	StationType        string         `json:",omitempty"` // AO1 (no precipitation sensor) or AO2
+	PeakWind           *PeakWind      `json:",omitempty"`
+	WindShift          *WindShift     `json:",omitempty"`
+	TempC              *float64       `json:",omitempty"` // T group, to a tenth of a degree
+	DewpointC          *float64       `json:",omitempty"`
+	SeaLevelPressureMb *float64       `json:",omitempty"`
+	MaxTemp6hrC        *float64       `json:",omitempty"` // 1snTTT
+	MinTemp6hrC        *float64       `json:",omitempty"` // 2snTTT
+	MaxTemp24hrC       *float64       `json:",omitempty"` // 4snTTTsnTTT
+	MinTemp24hrC       *float64       `json:",omitempty"`
+	WeatherEvents      []WeatherEvent `json:",omitempty"` // beginning and ending times, e.g. RAB15E30
+	Lightning          []Lightning    `json:",omitempty"`
+	MaintenanceNeeded  bool           `json:",omitempty"` // $ maintenance indicator
+	Other              []string       `json:",omitempty"` // groups not decoded
+}
+
+// PeakWind is the PK WND group, the highest wind since the last routine report
+type PeakWind struct {
+	DirDegrees int32
+	SpeedKt    int32
+	Time       time.Time
+}
+
+// WindShift is the WSHFT group
+type WindShift struct {
+	Time           time.Time
+	FrontalPassage bool // FROPA
+}
+
+// WeatherEvent is the time some weather began or ended
+type WeatherEvent struct {
+	Weather string
+	Began   bool // false if it ended
+	Time    time.Time
+}
+
+// Lightning is a LTG group with its frequency and location
+type Lightning struct {
+	Frequency string   `json:",omitempty"` // OCNL, FRQ or CONS
+	Types     []string `json:",omitempty"` // IC, CC, CG and CA
+	Location  string   `json:",omitempty"` // e.g. DSNT W, VC NE-S or OHD
+}
+
+var (
+	peakWindPattern    = regexp.MustCompile(`^(\d{3})(\d{2,3})/(\d{2})?(\d{2})$`)
+	remarkTimePattern  = regexp.MustCompile(`^(\d{2})?(\d{2})$`)
+	tempGroupPattern   = regexp.MustCompile(`^T([01]\d{3})([01]\d{3})?$`)
+	slpPattern         = regexp.MustCompile(`^SLP(\d{3})$`)
+	sixHourTempPattern = regexp.MustCompile(`^([12])([01]\d{3})$`)
+	dayTempPattern     = regexp.MustCompile(`^4([01]\d{3})([01]\d{3})$`)
+	eventsPattern      = regexp.MustCompile(`^(?:[A-Z+-]+?(?:[BE]\d{2}(?:\d{2})?)+)+$`)
+	eventPattern       = regexp.MustCompile(`([A-Z+-]+?)((?:[BE]\d{2}(?:\d{2})?)+)`)
+	eventTimePattern   = regexp.MustCompile(`([BE])(\d{2}(?:\d{2})?)`)
+	lightningPattern   = regexp.MustCompile(`^LTG((?:IC|CC|CG|CA)*)$`)
+	locationPattern    = regexp.MustCompile(`^(DSNT|VC|OHD|ALQDS|AND|[NSEW]{1,2}(-[NSEW]{1,2})?)$`)
+)
+
+// DecodeRemarks decodes the RMK section of RawText, stores the result in Remarks and returns it. It returns nil if
+// the METAR has no remarks.
+func (m *Metar) DecodeRemarks() *Remarks {
+	groups := strings.Fields(strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(m.RawText), "=")))
+	start := -1
+	for i, g := range groups {
+		if g == "RMK" {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		m.Remarks = nil
+		return nil
+	}
+	groups = groups[start:]
+
+	r := &Remarks{}
+	for i := 0; i < len(groups); i++ {
+		g := groups[i]
+
+		switch {
+		case g == "AO1" || g == "AO2":
+			r.StationType = g
+		case g == "$":
+			r.MaintenanceNeeded = true
+		case g == "PK" && i+2 < len(groups) && groups[i+1] == "WND" && peakWindPattern.MatchString(groups[i+2]):
+			s := peakWindPattern.FindStringSubmatch(groups[i+2])
+			r.PeakWind = &PeakWind{
+				DirDegrees: decode.Atoi(s[1]),
+				SpeedKt:    decode.Atoi(s[2]),
+				Time:       m.remarkTime(s[3], s[4]),
+			}
+			i += 2
+		case g == "WSHFT" && i+1 < len(groups) && remarkTimePattern.MatchString(groups[i+1]):
+			s := remarkTimePattern.FindStringSubmatch(groups[i+1])
+			r.WindShift = &WindShift{Time: m.remarkTime(s[1], s[2])}
+			i++
+			if i+1 < len(groups) && groups[i+1] == "FROPA" {
+				r.WindShift.FrontalPassage = true
+				i++
+			}
+		case tempGroupPattern.MatchString(g):
+			s := tempGroupPattern.FindStringSubmatch(g)
+			r.TempC = tenths(s[1])
+			if len(s[2]) > 0 {
+				r.DewpointC = tenths(s[2])
+			}
+		case slpPattern.MatchString(g):
+			p := float64(decode.Atoi(slpPattern.FindStringSubmatch(g)[1])) / 10
+			if p < 50 {
+				p += 1000
+			} else {
+				p += 900
+			}
+			r.SeaLevelPressureMb = &p
+		case sixHourTempPattern.MatchString(g):
+			s := sixHourTempPattern.FindStringSubmatch(g)
+			if s[1] == "1" {
+				r.MaxTemp6hrC = tenths(s[2])
+			} else {
+				r.MinTemp6hrC = tenths(s[2])
+			}
+		case dayTempPattern.MatchString(g):
+			s := dayTempPattern.FindStringSubmatch(g)
+			r.MaxTemp24hrC, r.MinTemp24hrC = tenths(s[1]), tenths(s[2])
+		case strings.HasPrefix(g, "LTG") || g == "OCNL" || g == "FRQ" || g == "CONS":
+			l, n := m.lightning(groups[i:])
+			if n == 0 {
+				r.Other = append(r.Other, g)
+				break
+			}
+			r.Lightning = append(r.Lightning, l)
+			i += n - 1
+		case eventsPattern.MatchString(g):
+			r.WeatherEvents = append(r.WeatherEvents, m.weatherEvents(g)...)
+		default:
+			r.Other = append(r.Other, g)
+		}
+	}
+
+	m.Remarks = r
+	return r
+}
+
+// lightning decodes a lightning group with an optional frequency before it and its location after it, returning
+// how many groups it used or 0 if groups does not start with one
+func (m *Metar) lightning(groups []string) (Lightning, int) {
+	var l Lightning
+	n := 0
+
+	switch groups[0] {
+	case "OCNL", "FRQ", "CONS":
+		l.Frequency = groups[0]
+		n++
+	}
+	if n >= len(groups) || !lightningPattern.MatchString(groups[n]) {
+		return Lightning{}, 0
+	}
+	types := lightningPattern.FindStringSubmatch(groups[n])[1]
+	for j := 0; j+2 <= len(types); j += 2 {
+		l.Types = append(l.Types, types[j:j+2])
+	}
+	n++
+
+	var location []string
+	for ; n < len(groups) && locationPattern.MatchString(groups[n]); n++ {
+		location = append(location, groups[n])
+	}
+	l.Location = strings.Join(location, " ")
+
+	return l, n
+}
+
+// weatherEvents decodes groups like RAB15E30SNB30 or TSB0159E30
+func (m *Metar) weatherEvents(g string) []WeatherEvent {
+	var events []WeatherEvent
+	for _, e := range eventPattern.FindAllStringSubmatch(g, -1) {
+		for _, t := range eventTimePattern.FindAllStringSubmatch(e[2], -1) {
+			hour, minute := "", t[2]
+			if len(t[2]) == 4 {
+				hour, minute = t[2][:2], t[2][2:]
+			}
+			events = append(events, WeatherEvent{Weather: e[1], Began: t[1] == "B", Time: m.remarkTime(hour, minute)})
+		}
+	}
+	return events
+}
+
+// remarkTime returns the last time at or before the observation with the given minute and, if given, hour
+func (m *Metar) remarkTime(hour, minute string) time.Time {
+	obs := m.ObservationTime
+	if len(hour) == 0 {
+		t := time.Date(obs.Year(), obs.Month(), obs.Day(), obs.Hour(), int(decode.Atoi(minute)), 0, 0, time.UTC)
+		if t.After(obs) {
+			t = t.Add(-time.Hour)
+		}
+		return t
+	}
+
+	t := time.Date(obs.Year(), obs.Month(), obs.Day(), int(decode.Atoi(hour)), int(decode.Atoi(minute)), 0, 0, time.UTC)
+	if t.After(obs) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// tenths decodes the snTTT temperatures of remark groups, s being 1 for negative temperatures
+func tenths(s string) *float64 {
+	c := float64(decode.Atoi(s[1:])) / 10
+	if s[0] == '1' {
+		c = -c
+	}
+	return &c
+}
@@ -0,0 +1,146 @@
+package metars
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// dump shows remarks with the values behind their pointers
+func dump(r *Remarks) string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func TestDecodeRemarks(t *testing.T) {
+	obs := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		want *Remarks
+	}{
+		{
+			name: "no remarks",
+			raw:  "KORD 181151Z 27015KT 10SM FEW250 12/M03 A3011",
+			want: nil,
+		},
+		{
+			name: "routine hourly",
+			raw:  "KORD 181151Z 27015G24KT 10SM FEW250 12/M03 A3011 RMK AO2 PK WND 28032/1128 SLP194 T01221033 $",
+			want: &Remarks{
+				StationType:        "AO2",
+				PeakWind:           &PeakWind{DirDegrees: 280, SpeedKt: 32, Time: obs(18, 11, 28)},
+				SeaLevelPressureMb: floatPtr(1019.4),
+				TempC:              floatPtr(12.2),
+				DewpointC:          floatPtr(-3.3),
+				MaintenanceNeeded:  true,
+			},
+		},
+		{
+			name: "low pressure and six hourly groups",
+			raw:  "KBOS 181153Z 02025G38KT 1SM RA BR OVC005 09/08 A2921 RMK AO2 PK WND 03045/56 WSHFT 1132 FROPA SLP893 T00890083 10106 20083 401221033",
+			want: &Remarks{
+				StationType:        "AO2",
+				PeakWind:           &PeakWind{DirDegrees: 30, SpeedKt: 45, Time: obs(18, 10, 56)},
+				WindShift:          &WindShift{Time: obs(18, 11, 32), FrontalPassage: true},
+				SeaLevelPressureMb: floatPtr(989.3),
+				TempC:              floatPtr(8.9),
+				DewpointC:          floatPtr(8.3),
+				MaxTemp6hrC:        floatPtr(10.6),
+				MinTemp6hrC:        floatPtr(8.3),
+				MaxTemp24hrC:       floatPtr(12.2),
+				MinTemp24hrC:       floatPtr(-3.3),
+			},
+		},
+		{
+			name: "wind shift without frontal passage and a temperature without dewpoint",
+			raw:  "KDEN 181205Z 32012KT 10SM CLR 08/M06 A3021 RMK AO2 WSHFT 1150 T0083",
+			want: &Remarks{
+				StationType: "AO2",
+				WindShift:   &WindShift{Time: obs(18, 11, 50)},
+				TempC:       floatPtr(8.3),
+			},
+		},
+		{
+			name: "weather events",
+			raw:  "KMSP 181153Z 31010KT 3SM -SN BR OVC012 M01/M02 A2990 RMK AO2 RAB15E30SNB30 TSB0959E1122",
+			want: &Remarks{
+				StationType: "AO2",
+				WeatherEvents: []WeatherEvent{
+					{Weather: "RA", Began: true, Time: obs(18, 11, 15)},
+					{Weather: "RA", Time: obs(18, 11, 30)},
+					{Weather: "SN", Began: true, Time: obs(18, 11, 30)},
+					{Weather: "TS", Began: true, Time: obs(18, 9, 59)},
+					{Weather: "TS", Time: obs(18, 11, 22)},
+				},
+			},
+		},
+		{
+			name: "minutes after the observation are from the previous hour",
+			raw:  "KATL 181205Z 18008KT 10SM -RA BKN030 20/18 A3001 RMK AO2 RAB55 PK WND 20028/58",
+			want: &Remarks{
+				StationType:   "AO2",
+				WeatherEvents: []WeatherEvent{{Weather: "RA", Began: true, Time: obs(18, 11, 55)}},
+				PeakWind:      &PeakWind{DirDegrees: 200, SpeedKt: 28, Time: obs(18, 11, 58)},
+			},
+		},
+		{
+			name: "times after the observation are from the previous day",
+			raw:  "KSEA 180005Z 18012KT 10SM -RA OVC030 12/10 A2990 RMK AO2 PK WND 19035/2350 RAB2340",
+			want: &Remarks{
+				StationType:   "AO2",
+				PeakWind:      &PeakWind{DirDegrees: 190, SpeedKt: 35, Time: obs(17, 23, 50)},
+				WeatherEvents: []WeatherEvent{{Weather: "RA", Began: true, Time: obs(17, 23, 40)}},
+			},
+		},
+		{
+			name: "lightning",
+			raw:  "KMIA 181153Z 09012KT 10SM TS SCT030CB 28/23 A3001 RMK AO2 FRQ LTGICCG VC NE-S LTGCG DSNT W",
+			want: &Remarks{
+				StationType: "AO2",
+				Lightning: []Lightning{
+					{Frequency: "FRQ", Types: []string{"IC", "CG"}, Location: "VC NE-S"},
+					{Types: []string{"CG"}, Location: "DSNT W"},
+				},
+			},
+		},
+		{
+			name: "weather event before lightning",
+			raw:  "KORD 181151Z 27015KT 10SM FEW250 12/M03 A3011 RMK AO2 RAB15 LTGICCG DSNT W",
+			want: &Remarks{
+				StationType:   "AO2",
+				WeatherEvents: []WeatherEvent{{Weather: "RA", Began: true, Time: obs(18, 11, 15)}},
+				Lightning:     []Lightning{{Types: []string{"IC", "CG"}, Location: "DSNT W"}},
+			},
+		},
+		{
+			name: "groups not decoded",
+			raw:  "KORD 181151Z 27015KT 10SM FEW250 12/M03 A3011 RMK AO1 CIG 015V025 OCNL SLPNO",
+			want: &Remarks{StationType: "AO1", Other: []string{"CIG", "015V025", "OCNL", "SLPNO"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseAt(tt.raw, testRef)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := m.DecodeRemarks()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeRemarks() = %s, want %s", dump(got), dump(tt.want))
+			}
+			if got != m.Remarks {
+				t.Error("DecodeRemarks did not store the remarks in the METAR")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package pireps
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+type Response struct {
+	XMLName      xml.Name   `xml:"response" json:"-"`
+	Version      string     `xml:"version,attr"`
+	RequestIndex int32      `xml:"request_index"`
+	Errors       []string   `xml:"errors>error"`
+	Warnings     []string   `xml:"warnings>warning"`
+	TimeTakenMs  int32      `xml:"time_taken_ms"`
+	DataSource   DataSource `xml:"data_source"`
+	Request      Request    `xml:"request"`
+	Data         Data       `xml:"data"`
+}
+
+type Request struct {
+	XMLName xml.Name `xml:"request" json:"-"`
+	Type    string   `xml:"type,attr"`
+}
+
+type DataSource struct {
+	XMLName xml.Name `xml:"data_source" json:"-"`
+	Name    string   `xml:"name,attr"`
+}
+
+type Data struct {
+	XMLName         xml.Name         `xml:"data" json:"-"`
+	NumResults      int32            `xml:"num_results,attr"`
+	AircraftReports []AircraftReport `xml:"AircraftReport"`
+}
+
+type QualityControlFlags struct {
+	XMLName                   xml.Name `xml:"quality_control_flags" json:"-"`
+	MidPointAssumed           bool     `xml:"mid_point_assumed"`
+	NoTimeStamp               bool     `xml:"no_time_stamp"`
+	FltLvlRange               bool     `xml:"flt_lvl_range"`
+	AboveGroundLevelIndicated bool     `xml:"above_ground_level_indicated"`
+	NoFltLvl                  bool     `xml:"no_flt_lvl"`
+	BadLocation               bool     `xml:"bad_location"`
+}
+
+type SkyCondition struct {
+	XMLName        xml.Name `xml:"sky_condition" json:"-"`
+	SkyCover       string   `xml:"sky_cover,attr"`
+	CloudBaseFtMSL int32    `xml:"cloud_base_ft_msl,attr"`
+	CloudTopFtMSL  int32    `xml:"cloud_top_ft_msl,attr"`
+}
+
+type TurbulenceCondition struct {
+	XMLName             xml.Name `xml:"turbulence_condition" json:"-"`
+	TurbulenceType      string   `xml:"turbulence_type,attr"`
+	TurbulenceIntensity string   `xml:"turbulence_intensity,attr"`
+	TurbulenceBaseFtMSL int32    `xml:"turbulence_base_ft_msl,attr"`
+	TurbulenceTopFtMSL  int32    `xml:"turbulence_top_ft_msl,attr"`
+	TurbulenceFrequency string   `xml:"turbulence_freq,attr"`
+}
+
+type IcingCondition struct {
+	XMLName        xml.Name `xml:"icing_condition" json:"-"`
+	IcingType      string   `xml:"icing_type,attr"`
+	IcingIntensity string   `xml:"icing_intensity,attr"`
+	IcingBaseFtMSL int32    `xml:"icing_base_ft_msl,attr"`
+	IcingTopFtMSL  int32    `xml:"icing_top_ft_msl,attr"`
+}
+
+type AircraftReport struct {
+	XMLName             xml.Name              `xml:"AircraftReport" json:"-"`
+	ReceiptTime         time.Time             `xml:"receipt_time"`
+	ObservationTime     time.Time             `xml:"observation_time"`
+	QualityControlFlags QualityControlFlags   `xml:"quality_control_flags"`
+	AircraftRef         string                `xml:"aircraft_ref"`
+	Latitude            float64               `xml:"latitude"`
+	Longitude           float64               `xml:"longitude"`
+	AltitudeFtMSL       int32                 `xml:"altitude_ft_msl"`
+	SkyCondition        []SkyCondition        `xml:"sky_condition"`
+	TurbulenceCondition []TurbulenceCondition `xml:"turbulence_condition"`
+	IcingCondition      []IcingCondition      `xml:"icing_condition"`
+	VisibilityStatuteMi float64               `xml:"visibility_statute_mi"`
+	WxString            string                `xml:"wx_string"`
+	TempC               float64               `xml:"temp_c"`
+	WindDirDegrees      int32                 `xml:"wind_dir_degrees"`
+	WindSpeedKt         int32                 `xml:"wind_speed_kt"`
+	VertGustKt          int32                 `xml:"vert_gust_kt"`
+	ReportType          string                `xml:"report_type"`
+	RawText             string                `xml:"raw_text"`
+}
+
+func (r *Response) ToRawTextOnly() (s []string) {
+	for _, report := range r.Data.AircraftReports {
+		s = append(s, report.RawText)
+	}
+	return
+}
+
+func (r *Response) ToJson() (s string, err error) {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
+
+func (r *Response) ToJsonIndented() (s string, err error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
@@ -0,0 +1,79 @@
+package pireps
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeXML(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/adds_pireps.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Response
+	if err := xml.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Version != "1.2" || r.RequestIndex != 97112840 || r.DataSource.Name != "aircraftreports" || r.TimeTakenMs != 11 {
+		t.Errorf("response version/request index/data source/time = %s/%d/%s/%d",
+			r.Version, r.RequestIndex, r.DataSource.Name, r.TimeTakenMs)
+	}
+	if r.Data.NumResults != 2 || len(r.Data.AircraftReports) != 2 {
+		t.Fatalf("got %d reports (num_results %d), want 2", len(r.Data.AircraftReports), r.Data.NumResults)
+	}
+
+	pirep := r.Data.AircraftReports[0]
+	if !pirep.ObservationTime.Equal(time.Date(2026, 10, 18, 11, 55, 0, 0, time.UTC)) ||
+		!pirep.ReceiptTime.Equal(time.Date(2026, 10, 18, 11, 58, 21, 0, time.UTC)) {
+		t.Errorf("observation/receipt time = %v/%v", pirep.ObservationTime, pirep.ReceiptTime)
+	}
+	if pirep.AircraftRef != "B738" || pirep.Latitude != 39.6 || pirep.Longitude != -105.2 || pirep.AltitudeFtMSL != 17000 ||
+		pirep.TempC != -12 || pirep.WindDirDegrees != 270 || pirep.WindSpeedKt != 45 || pirep.ReportType != "PIREP" {
+		t.Errorf("PIREP = %+v", pirep)
+	}
+	if want := (QualityControlFlags{NoTimeStamp: true, AboveGroundLevelIndicated: true}); !equalFlags(pirep.QualityControlFlags, want) {
+		t.Errorf("quality_control_flags = %+v, want %+v", pirep.QualityControlFlags, want)
+	}
+
+	if len(pirep.SkyCondition) != 1 || len(pirep.TurbulenceCondition) != 1 || len(pirep.IcingCondition) != 1 {
+		t.Fatalf("sky/turbulence/icing = %+v/%+v/%+v, want one of each",
+			pirep.SkyCondition, pirep.TurbulenceCondition, pirep.IcingCondition)
+	}
+	if sky := pirep.SkyCondition[0]; sky.SkyCover != "OVC" || sky.CloudBaseFtMSL != 12000 || sky.CloudTopFtMSL != 16000 {
+		t.Errorf("sky_condition = %+v", sky)
+	}
+	turb := pirep.TurbulenceCondition[0]
+	turb.XMLName = xml.Name{}
+	if want := (TurbulenceCondition{TurbulenceType: "CHOP", TurbulenceIntensity: "MOD", TurbulenceBaseFtMSL: 15000,
+		TurbulenceTopFtMSL: 19000, TurbulenceFrequency: "OCNL"}); !reflect.DeepEqual(turb, want) {
+		t.Errorf("turbulence_condition = %+v, want %+v", turb, want)
+	}
+	ice := pirep.IcingCondition[0]
+	ice.XMLName = xml.Name{}
+	if want := (IcingCondition{IcingType: "RIME", IcingIntensity: "LGT", IcingBaseFtMSL: 12000, IcingTopFtMSL: 16000}); !reflect.DeepEqual(ice, want) {
+		t.Errorf("icing_condition = %+v, want %+v", ice, want)
+	}
+
+	airep := r.Data.AircraftReports[1]
+	if airep.ReportType != "AIREP" || airep.VisibilityStatuteMi != 10 || airep.WxString != "-SHRA" || airep.VertGustKt != 8 ||
+		airep.TempC != -51 || airep.WindSpeedKt != 0 {
+		t.Errorf("AIREP = %+v", airep)
+	}
+	if !equalFlags(airep.QualityControlFlags, QualityControlFlags{}) || airep.SkyCondition != nil {
+		t.Errorf("AIREP flags/sky = %+v/%+v, want none", airep.QualityControlFlags, airep.SkyCondition)
+	}
+
+	if got := r.ToRawTextOnly(); len(got) != 2 || got[1] != "ARP UAL123 4400N 11030W 1201 F350 MS51 260/075KT TB LGT" {
+		t.Errorf("ToRawTextOnly() = %q", got)
+	}
+}
+
+// equalFlags compares quality control flags without the XMLName the decoder fills in
+func equalFlags(a, b QualityControlFlags) bool {
+	a.XMLName, b.XMLName = xml.Name{}, xml.Name{}
+	return a == b
+}
@@ -0,0 +1,116 @@
+package stations
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:generate go run gencatalog.go
+
+// catalogCSV is the station catalog built by gencatalog.go from the stations data source. Its columns are
+// station_id, wmo_id, latitude, longitude, elevation_m, site, state, country and site_type, the latter holding the
+// products separated by spaces.
+//
+//go:embed catalog.csv
+var catalogCSV string
+
+var (
+	catalogOnce  sync.Once
+	catalog      *Index
+	catalogError error
+)
+
+// seedMarker starts the first line of the hand-written catalog.csv that stands in for the generated one. It holds only
+// major airports, go generate replaces it with all stations of the stations data source.
+const seedMarker = "# seed"
+
+// Catalog returns an index over the station catalog embedded in the binary, for lookups that need no network
+// round trip. The catalog is parsed on first use.
+func Catalog() (*Index, error) {
+	catalogOnce.Do(func() {
+		var s []Station
+		s, catalogError = ReadCatalog(strings.NewReader(catalogCSV))
+		if catalogError == nil {
+			catalog = NewIndex(s)
+		}
+	})
+
+	return catalog, catalogError
+}
+
+// CatalogComplete reports whether the embedded catalog was generated from the stations data source. When it is not,
+// the catalog is the seed of major airports and lookups in it miss most stations.
+func CatalogComplete() bool {
+	return !strings.HasPrefix(catalogCSV, seedMarker)
+}
+
+// ReadCatalog reads stations in the catalog CSV format, skipping the header row and lines starting with #
+func ReadCatalog(r io.Reader) ([]Station, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 9
+	cr.Comment = '#'
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var stations []Station
+	for i, rec := range records {
+		if i == 0 {
+			continue
+		}
+
+		s := Station{
+			StationId: rec[0],
+			WmoId:     rec[1],
+			Site:      rec[5],
+			State:     rec[6],
+			Country:   rec[7],
+			SiteType:  strings.Fields(rec[8]),
+		}
+		if s.Latitude, err = strconv.ParseFloat(rec[2], 64); err != nil {
+			return nil, fmt.Errorf("catalog record %d: %v", i+1, err)
+		}
+		if s.Longitude, err = strconv.ParseFloat(rec[3], 64); err != nil {
+			return nil, fmt.Errorf("catalog record %d: %v", i+1, err)
+		}
+		if len(rec[4]) > 0 {
+			if s.ElevationM, err = strconv.ParseFloat(rec[4], 64); err != nil {
+				return nil, fmt.Errorf("catalog record %d: %v", i+1, err)
+			}
+		}
+
+		stations = append(stations, s)
+	}
+
+	return stations, nil
+}
+
+// WriteCatalog writes stations in the catalog CSV format, including the header row
+func WriteCatalog(w io.Writer, stations []Station) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"station_id", "wmo_id", "latitude", "longitude", "elevation_m", "site", "state", "country", "site_type"})
+
+	for _, s := range stations {
+		cw.Write([]string{
+			s.StationId,
+			s.WmoId,
+			strconv.FormatFloat(s.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(s.Longitude, 'f', -1, 64),
+			strconv.FormatFloat(s.ElevationM, 'f', -1, 64),
+			s.Site,
+			s.State,
+			s.Country,
+			strings.Join(s.SiteType, " "),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
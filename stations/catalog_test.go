@@ -0,0 +1,55 @@
+package stations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadCatalog(t *testing.T) {
+	csv := `# seed catalog
+station_id,wmo_id,latitude,longitude,elevation_m,site,state,country,site_type
+KORD,72530,41.98,-87.93,201,Chicago/O'Hare Intl,IL,US,METAR TAF
+KXYZ,,40.5,-90.25,,Nowhere,IL,US,
+`
+	s, err := ReadCatalog(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatalf("read %d stations, want 2", len(s))
+	}
+	if s[0].StationId != "KORD" || s[0].ElevationM != 201 || len(s[0].SiteType) != 2 {
+		t.Errorf("stations[0] = %+v", s[0])
+	}
+	if s[1].ElevationM != 0 || len(s[1].SiteType) != 0 {
+		t.Errorf("stations[1] = %+v", s[1])
+	}
+
+	if _, err := ReadCatalog(strings.NewReader("station_id,wmo_id,latitude,longitude,elevation_m,site,state,country,site_type\nKORD,,north,-87.93,,,,,\n")); err == nil {
+		t.Error("ReadCatalog() accepted an invalid latitude")
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	c, err := Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := c.Nearest(41.9, -87.9, 1, nil); len(r) != 1 {
+		t.Fatalf("Nearest() = %v, want one station", r)
+	}
+}
+
+func TestCatalogComplete(t *testing.T) {
+	defer func(saved string) { catalogCSV = saved }(catalogCSV)
+
+	catalogCSV = "# seed catalog of major airports only\nstation_id,wmo_id,latitude,longitude,elevation_m,site,state,country,site_type\n"
+	if CatalogComplete() {
+		t.Error("CatalogComplete() = true for the seed catalog")
+	}
+
+	catalogCSV = "station_id,wmo_id,latitude,longitude,elevation_m,site,state,country,site_type\n"
+	if !CatalogComplete() {
+		t.Error("CatalogComplete() = false for a generated catalog")
+	}
+}
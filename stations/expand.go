@@ -0,0 +1,134 @@
+package stations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrIndexIncomplete is wrapped by the error Expand returns for a pattern it would have to look up in an index marked
+// with IndexIncomplete
+var ErrIndexIncomplete = errors.New("the station index is incomplete and would miss stations")
+
+// Expander expands the entries of a station list. Besides station identifiers an entry may be
+//
+//	@STATE   all stations in a state or province, e.g. @WA
+//	~COUNTRY all stations in a country, e.g. ~CA
+//	PREFIX*  all stations whose identifier starts with PREFIX, e.g. PH*
+//	NAME     a named group from Groups
+//
+// States, countries and prefixes are looked up in Index unless the data server is left to expand them.
+type Expander struct {
+	Groups map[string][]string // members of each group, which may be any kind of entry including other groups
+	Index  *Index
+	// IndexIncomplete marks an Index that holds only some stations, like the seed catalog, see CatalogComplete.
+	// Patterns that would be looked up in it fail instead of silently expanding to part of their stations.
+	IndexIncomplete bool
+
+	PassStates    bool // keep @STATE entries for the data server
+	PassCountries bool // keep ~COUNTRY entries for the data server
+	PassPrefixes  bool // keep PREFIX* entries for the data server
+}
+
+// Expand returns entries with groups, and the patterns that are not passed through, replaced by station identifiers.
+// Duplicates are removed, keeping the first occurrence.
+func (e *Expander) Expand(entries []string) ([]string, error) {
+	x := expansion{seen: make(map[string]bool)}
+	if err := e.expand(&x, entries, nil); err != nil {
+		return nil, err
+	}
+	return x.result, nil
+}
+
+type expansion struct {
+	result []string
+	seen   map[string]bool
+}
+
+func (x *expansion) add(entry string) {
+	key := strings.ToUpper(entry)
+	if !x.seen[key] {
+		x.seen[key] = true
+		x.result = append(x.result, entry)
+	}
+}
+
+// expand adds entries to x, path holds the groups being expanded to detect groups that contain themselves
+func (e *Expander) expand(x *expansion, entries []string, path []string) error {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		switch {
+		case entry == "":
+		case e.group(entry) != nil:
+			name := strings.ToLower(entry)
+			for _, p := range path {
+				if p == name {
+					return fmt.Errorf("station group %q contains itself", entry)
+				}
+			}
+			if err := e.expand(x, e.group(entry), append(path, name)); err != nil {
+				return err
+			}
+		case strings.HasPrefix(entry, "@"):
+			if err := e.match(x, entry, e.PassStates, func(s *Station) bool {
+				return strings.EqualFold(s.State, entry[1:])
+			}); err != nil {
+				return err
+			}
+		case strings.HasPrefix(entry, "~"):
+			if err := e.match(x, entry, e.PassCountries, func(s *Station) bool {
+				return strings.EqualFold(s.Country, entry[1:])
+			}); err != nil {
+				return err
+			}
+		case strings.HasSuffix(entry, "*"):
+			prefix := strings.ToUpper(strings.TrimSuffix(entry, "*"))
+			if err := e.match(x, entry, e.PassPrefixes, func(s *Station) bool {
+				return strings.HasPrefix(strings.ToUpper(s.StationId), prefix)
+			}); err != nil {
+				return err
+			}
+		default:
+			x.add(entry)
+		}
+	}
+
+	return nil
+}
+
+// group returns the members of the group named entry, compared case-insensitively, or nil
+func (e *Expander) group(entry string) []string {
+	for name, members := range e.Groups {
+		if strings.EqualFold(name, entry) {
+			if members == nil {
+				return []string{}
+			}
+			return members
+		}
+	}
+	return nil
+}
+
+// match adds entry itself when pass is set, otherwise the stations of the index selected by filter
+func (e *Expander) match(x *expansion, entry string, pass bool, filter Filter) error {
+	if pass {
+		x.add(entry)
+		return nil
+	}
+	if e.Index == nil {
+		return fmt.Errorf("cannot expand %q without a station index", entry)
+	}
+	if e.IndexIncomplete {
+		return fmt.Errorf("cannot expand %q, %w", entry, ErrIndexIncomplete)
+	}
+
+	selected := e.Index.Select(filter)
+	if len(selected) == 0 {
+		return fmt.Errorf("no stations match %q", entry)
+	}
+	for _, s := range selected {
+		x.add(s.StationId)
+	}
+	return nil
+}
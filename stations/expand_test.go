@@ -0,0 +1,69 @@
+package stations
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testIndex = NewIndex([]Station{
+	{StationId: "KSEA", State: "WA", Country: "US"},
+	{StationId: "KGEG", State: "WA", Country: "US"},
+	{StationId: "KPDX", State: "OR", Country: "US"},
+	{StationId: "PHNL", State: "HI", Country: "US"},
+	{StationId: "PHOG", State: "HI", Country: "US"},
+	{StationId: "CYVR", State: "BC", Country: "CA"},
+})
+
+func TestExpand(t *testing.T) {
+	groups := map[string][]string{
+		"northwest": {"@WA", "KPDX", "islands"},
+		"islands":   {"PH*"},
+		"empty":     nil,
+		"loop":      {"KSEA", "loop2"},
+		"loop2":     {"Loop"},
+	}
+
+	tests := []struct {
+		name    string
+		e       Expander
+		entries []string
+		want    []string
+		wantErr string
+	}{
+		{"identifiers", Expander{}, []string{"KSEA", " kpdx ", "", "KSEA"}, []string{"KSEA", "kpdx"}, ""},
+		{"state", Expander{Index: testIndex}, []string{"@wa"}, []string{"KSEA", "KGEG"}, ""},
+		{"country", Expander{Index: testIndex}, []string{"~CA"}, []string{"CYVR"}, ""},
+		{"prefix", Expander{Index: testIndex}, []string{"ph*"}, []string{"PHNL", "PHOG"}, ""},
+		{"nested groups", Expander{Groups: groups, Index: testIndex}, []string{"NorthWest", "KSEA"}, []string{"KSEA", "KGEG", "KPDX", "PHNL", "PHOG"}, ""},
+		{"empty group", Expander{Groups: groups}, []string{"empty", "KSEA"}, []string{"KSEA"}, ""},
+		{"passed to the data server", Expander{Groups: groups, PassStates: true, PassCountries: true, PassPrefixes: true}, []string{"northwest", "~CA"}, []string{"@WA", "KPDX", "PH*", "~CA"}, ""},
+		{"group cycle", Expander{Groups: groups}, []string{"loop"}, nil, `station group "Loop" contains itself`},
+		{"no match", Expander{Index: testIndex}, []string{"@TX"}, nil, `no stations match "@TX"`},
+		{"no index", Expander{}, []string{"@WA"}, nil, `cannot expand "@WA" without a station index`},
+		{"incomplete index", Expander{Index: testIndex, IndexIncomplete: true}, []string{"KPDX", "@WA"}, nil, `cannot expand "@WA", the station index is incomplete`},
+		{"incomplete index with passed patterns", Expander{Index: testIndex, IndexIncomplete: true, PassStates: true}, []string{"@WA"}, []string{"@WA"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.e.Expand(tt.entries)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expand() error = %v, want %q", err, tt.wantErr)
+				}
+				if incomplete := errors.Is(err, ErrIndexIncomplete); incomplete != tt.e.IndexIncomplete {
+					t.Errorf("errors.Is(%v, ErrIndexIncomplete) = %v", err, incomplete)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
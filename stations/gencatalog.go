@@ -0,0 +1,39 @@
+//go:build ignore
+// +build ignore
+
+// gencatalog downloads all stations from the stations data source and writes them to catalog.csv.
+// Run it with go generate from the stations directory.
+package main
+
+import (
+	"log"
+	"os"
+	"sort"
+
+	"github.com/theperiscope/avwx/api"
+	"github.com/theperiscope/avwx/stations"
+)
+
+func main() {
+	client := api.NewClient(api.DefaultApiEndPoint, api.WithADDSErrors(false), api.WithRetryPolicy(api.DefaultRetryPolicy))
+
+	r, err := client.GetStations(api.StationInfoOptions{MinLat: api.Float(-90), MaxLat: api.Float(90), MinLon: api.Float(-180), MaxLon: api.Float(180)})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := r.Data.Stations
+	sort.Slice(s, func(i, j int) bool { return s[i].StationId < s[j].StationId })
+
+	f, err := os.Create("catalog.csv")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := stations.WriteCatalog(f, s); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("wrote %d stations", len(s))
+}
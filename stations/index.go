@@ -0,0 +1,228 @@
+package stations
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/theperiscope/avwx/geo"
+)
+
+// Index answers nearest-station and within-radius queries over a fixed set of stations. It is a k-d tree over the
+// stations' positions on the unit sphere, where the straight-line (chord) distance orders points the same way as
+// the great-circle distance does.
+type Index struct {
+	stations []Station
+	byId     map[string]int
+	root     *kdNode
+}
+
+// Result is a station found by an Index query together with its distance from the query point
+type Result struct {
+	Station    Station
+	DistanceSM float64
+}
+
+// Filter selects the stations a query may return, nil selects all
+type Filter func(*Station) bool
+
+// HasProducts returns a Filter selecting stations that issue all of products, e.g. HasProducts("TAF")
+func HasProducts(products ...string) Filter {
+	return func(s *Station) bool {
+		for _, p := range products {
+			if !s.Has(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+type kdNode struct {
+	station     int
+	p           [3]float64
+	axis        int
+	left, right *kdNode
+}
+
+func NewIndex(stations []Station) *Index {
+	ix := &Index{
+		stations: stations,
+		byId:     make(map[string]int, len(stations)),
+	}
+
+	nodes := make([]kdNode, len(stations))
+	for i := range stations {
+		ix.byId[strings.ToUpper(stations[i].StationId)] = i
+		nodes[i] = kdNode{station: i, p: toUnitVector(stations[i].Latitude, stations[i].Longitude)}
+	}
+
+	ix.root = build(nodes, 0)
+	return ix
+}
+
+func build(nodes []kdNode, depth int) *kdNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	axis := depth % 3
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].p[axis] < nodes[j].p[axis] })
+
+	m := len(nodes) / 2
+	n := nodes[m]
+	n.axis = axis
+	n.left = build(nodes[:m], depth+1)
+	n.right = build(nodes[m+1:], depth+1)
+	return &n
+}
+
+// Len returns the number of stations in the index
+func (ix *Index) Len() int {
+	return len(ix.stations)
+}
+
+// Lookup returns the station with the given identifier, compared case-insensitively
+func (ix *Index) Lookup(stationId string) (Station, bool) {
+	i, ok := ix.byId[strings.ToUpper(stationId)]
+	if !ok {
+		return Station{}, false
+	}
+	return ix.stations[i], true
+}
+
+// Select returns the stations selected by filter in index order
+func (ix *Index) Select(filter Filter) []Station {
+	var selected []Station
+	for i := range ix.stations {
+		if filter == nil || filter(&ix.stations[i]) {
+			selected = append(selected, ix.stations[i])
+		}
+	}
+	return selected
+}
+
+// Nearest returns up to n stations selected by filter, closest first
+func (ix *Index) Nearest(lat, lon float64, n int, filter Filter) []Result {
+	if n <= 0 {
+		return nil
+	}
+
+	q := toUnitVector(lat, lon)
+	h := &resultHeap{}
+	var search func(node *kdNode)
+	search = func(node *kdNode) {
+		if node == nil {
+			return
+		}
+
+		if filter == nil || filter(&ix.stations[node.station]) {
+			d := chord2(q, node.p)
+			if h.Len() < n {
+				heap.Push(h, candidate{node.station, d})
+			} else if d < (*h)[0].chord2 {
+				(*h)[0] = candidate{node.station, d}
+				heap.Fix(h, 0)
+			}
+		}
+
+		diff := q[node.axis] - node.p[node.axis]
+		near, far := node.left, node.right
+		if diff > 0 {
+			near, far = far, near
+		}
+
+		search(near)
+		if h.Len() < n || diff*diff < (*h)[0].chord2 {
+			search(far)
+		}
+	}
+	search(ix.root)
+
+	return ix.results(lat, lon, *h)
+}
+
+// Within returns all stations selected by filter within radiusSM statute miles, closest first
+func (ix *Index) Within(lat, lon float64, radiusSM float64, filter Filter) []Result {
+	if radiusSM < 0 {
+		return nil
+	}
+
+	// chord length matching the radius, the whole sphere if the radius reaches around it
+	maxChord := 2.0
+	if angle := radiusSM / geo.EarthRadiusSM; angle < math.Pi {
+		maxChord = 2 * math.Sin(angle/2)
+	}
+	maxChord2 := maxChord * maxChord
+
+	q := toUnitVector(lat, lon)
+	var found []candidate
+	var search func(node *kdNode)
+	search = func(node *kdNode) {
+		if node == nil {
+			return
+		}
+
+		if d := chord2(q, node.p); d <= maxChord2 && (filter == nil || filter(&ix.stations[node.station])) {
+			found = append(found, candidate{node.station, d})
+		}
+
+		diff := q[node.axis] - node.p[node.axis]
+		if diff <= maxChord {
+			search(node.left)
+		}
+		if -diff <= maxChord {
+			search(node.right)
+		}
+	}
+	search(ix.root)
+
+	return ix.results(lat, lon, found)
+}
+
+// results converts candidates into Results sorted by distance
+func (ix *Index) results(lat, lon float64, candidates []candidate) []Result {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].chord2 < candidates[j].chord2 })
+
+	q := geo.Point{Lat: lat, Lon: lon}
+	results := make([]Result, 0, len(candidates))
+	for _, c := range candidates {
+		s := ix.stations[c.station]
+		results = append(results, Result{
+			Station:    s,
+			DistanceSM: geo.DistanceSM(q, geo.Point{Lat: s.Latitude, Lon: s.Longitude}),
+		})
+	}
+	return results
+}
+
+func toUnitVector(lat, lon float64) [3]float64 {
+	phi, lambda := lat*math.Pi/180, lon*math.Pi/180
+	return [3]float64{math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)}
+}
+
+// chord2 returns the squared straight-line distance between two unit vectors
+func chord2(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+type candidate struct {
+	station int
+	chord2  float64
+}
+
+// resultHeap is a max-heap of candidates so the farthest of the current n nearest is at the top
+type resultHeap []candidate
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].chord2 > h[j].chord2 }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,172 @@
+package stations
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/theperiscope/avwx/geo"
+)
+
+// randomStations returns n stations spread over the sphere, with a share of them crowded at the antimeridian and
+// the poles where longitudes wrap and converge. About half of them issue TAFs.
+func randomStations(rnd *rand.Rand, n int) []Station {
+	s := make([]Station, n)
+	for i := range s {
+		lat, lon := randomPoint(rnd)
+		s[i] = Station{StationId: fmt.Sprintf("S%03d", i), Latitude: lat, Longitude: lon, SiteType: SiteType{"METAR"}}
+		if rnd.Intn(2) == 0 {
+			s[i].SiteType = append(s[i].SiteType, "TAF")
+		}
+	}
+	return s
+}
+
+func randomPoint(rnd *rand.Rand) (lat, lon float64) {
+	switch rnd.Intn(4) {
+	case 0: // either side of the antimeridian
+		lat = rnd.Float64()*120 - 60
+		lon = 180 - rnd.Float64()*3
+		if rnd.Intn(2) == 0 {
+			lon = -lon
+		}
+	case 1: // close to one of the poles
+		lat = 90 - rnd.Float64()*4
+		if rnd.Intn(2) == 0 {
+			lat = -lat
+		}
+		lon = rnd.Float64()*360 - 180
+	default: // uniform on the sphere
+		lat = math.Asin(2*rnd.Float64()-1) * 180 / math.Pi
+		lon = rnd.Float64()*360 - 180
+	}
+	return
+}
+
+// bruteForce returns every station selected by filter with its distance, closest first
+func bruteForce(stations []Station, lat, lon float64, filter Filter) []Result {
+	var all []Result
+	for i := range stations {
+		if filter != nil && !filter(&stations[i]) {
+			continue
+		}
+		d := geo.DistanceSM(geo.Point{Lat: lat, Lon: lon}, geo.Point{Lat: stations[i].Latitude, Lon: stations[i].Longitude})
+		all = append(all, Result{Station: stations[i], DistanceSM: d})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].DistanceSM < all[j].DistanceSM })
+	return all
+}
+
+// sameResults compares results by station and distance. Random coordinates make ties unlikely, so the order must
+// match as well.
+func sameResults(got, want []Result) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i].Station.StationId != want[i].Station.StationId || math.Abs(got[i].DistanceSM-want[i].DistanceSM) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func ids(results []Result) []string {
+	s := make([]string, len(results))
+	for i, r := range results {
+		s[i] = fmt.Sprintf("%s@%.3f", r.Station.StationId, r.DistanceSM)
+	}
+	return s
+}
+
+var indexFilters = []struct {
+	name   string
+	filter Filter
+}{
+	{"all", nil},
+	{"TAF", HasProducts("TAF")},
+	{"METAR and TAF", HasProducts("METAR", "TAF")},
+	{"none", HasProducts("rawinsonde")},
+}
+
+func TestNearestMatchesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	stations := randomStations(rnd, 400)
+	ix := NewIndex(stations)
+
+	for q := 0; q < 300; q++ {
+		lat, lon := randomPoint(rnd)
+		n := 1 + rnd.Intn(12)
+		for _, f := range indexFilters {
+			want := bruteForce(stations, lat, lon, f.filter)
+			if len(want) > n {
+				want = want[:n]
+			}
+			if got := ix.Nearest(lat, lon, n, f.filter); !sameResults(got, want) {
+				t.Fatalf("Nearest(%.4f, %.4f, %d, %s) = %v, want %v", lat, lon, n, f.name, ids(got), ids(want))
+			}
+		}
+	}
+}
+
+func TestWithinMatchesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	stations := randomStations(rnd, 400)
+	ix := NewIndex(stations)
+
+	for q := 0; q < 300; q++ {
+		lat, lon := randomPoint(rnd)
+		radius := rnd.Float64() * 1500
+		if q%50 == 0 {
+			radius = 15000 // reaches around the earth
+		}
+		for _, f := range indexFilters {
+			var want []Result
+			for _, r := range bruteForce(stations, lat, lon, f.filter) {
+				if r.DistanceSM <= radius {
+					want = append(want, r)
+				}
+			}
+			got := ix.Within(lat, lon, radius, f.filter)
+			// a station right on the circle may fall on either side of it
+			if len(got) != len(want) && len(got) > 0 && len(want) > 0 &&
+				math.Abs(math.Max(got[len(got)-1].DistanceSM, want[len(want)-1].DistanceSM)-radius) < 1e-6 {
+				continue
+			}
+			if !sameResults(got, want) {
+				t.Fatalf("Within(%.4f, %.4f, %.1f, %s) = %v, want %v", lat, lon, radius, f.name, ids(got), ids(want))
+			}
+		}
+	}
+}
+
+func TestIndexEdgeCases(t *testing.T) {
+	stations := []Station{
+		{StationId: "EAST", Latitude: 10, Longitude: 179.9},
+		{StationId: "WEST", Latitude: 10, Longitude: -179.9},
+		{StationId: "NPOL", Latitude: 89.99, Longitude: 0},
+		{StationId: "NPOM", Latitude: 89.99, Longitude: 180},
+	}
+	ix := NewIndex(stations)
+
+	// across the antimeridian WEST is about 10 miles away, not most of the way around the earth
+	if r := ix.Nearest(10, 179.95, 2, nil); len(r) != 2 || r[1].Station.StationId != "WEST" || r[1].DistanceSM > 11 {
+		t.Errorf("Nearest across the antimeridian = %v, want EAST and WEST within 11 miles", ids(r))
+	}
+	// stations on opposite meridians meet at the pole
+	if r := ix.Within(90, 0, 2, nil); len(r) != 2 || r[0].Station.StationId[:3] != "NPO" {
+		t.Errorf("Within at the pole = %v, want both polar stations", ids(r))
+	}
+
+	if r := ix.Nearest(0, 0, 0, nil); r != nil {
+		t.Errorf("Nearest with n = 0 = %v, want nil", ids(r))
+	}
+	if r := ix.Within(0, 0, -1, nil); r != nil {
+		t.Errorf("Within with a negative radius = %v, want nil", ids(r))
+	}
+	if r := NewIndex(nil).Nearest(0, 0, 3, nil); len(r) != 0 {
+		t.Errorf("Nearest in an empty index = %v, want none", ids(r))
+	}
+}
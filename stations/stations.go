@@ -0,0 +1,128 @@
+package stations
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type Response struct {
+	XMLName      xml.Name   `xml:"response" json:"-"`
+	Version      string     `xml:"version,attr"`
+	RequestIndex int32      `xml:"request_index"`
+	Errors       []string   `xml:"errors>error"`
+	Warnings     []string   `xml:"warnings>warning"`
+	TimeTakenMs  int32      `xml:"time_taken_ms"`
+	DataSource   DataSource `xml:"data_source"`
+	Request      Request    `xml:"request"`
+	Data         Data       `xml:"data"`
+}
+
+type Request struct {
+	XMLName xml.Name `xml:"request" json:"-"`
+	Type    string   `xml:"type,attr"`
+}
+
+type DataSource struct {
+	XMLName xml.Name `xml:"data_source" json:"-"`
+	Name    string   `xml:"name,attr"`
+}
+
+type Data struct {
+	XMLName    xml.Name  `xml:"data" json:"-"`
+	NumResults int32     `xml:"num_results,attr"`
+	Stations   []Station `xml:"Station"`
+}
+
+// SiteType lists the products a station issues, e.g. METAR, TAF, rawinsonde, NEXRAD, wind_profiler, WFO_office, SYNOPS.
+// The data server reports each of them as an empty element inside site_type.
+type SiteType []string
+
+func (s *SiteType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			*s = append(*s, t.Name.Local)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type Station struct {
+	XMLName    xml.Name `xml:"Station" json:"-"`
+	StationId  string   `xml:"station_id"`
+	WmoId      string   `xml:"wmo_id"`
+	Latitude   float64  `xml:"latitude"`
+	Longitude  float64  `xml:"longitude"`
+	ElevationM float64  `xml:"elevation_m"`
+	Site       string   `xml:"site"`
+	State      string   `xml:"state"`
+	Country    string   `xml:"country"`
+	SiteType   SiteType `xml:"site_type"`
+}
+
+// Has reports whether the station issues product, compared case-insensitively
+func (s *Station) Has(product string) bool {
+	for _, p := range s.SiteType {
+		if strings.EqualFold(p, product) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Station) HasMetar() bool {
+	return s.Has("METAR")
+}
+
+func (s *Station) HasTaf() bool {
+	return s.Has("TAF")
+}
+
+func (s *Station) HasRawinsonde() bool {
+	return s.Has("rawinsonde")
+}
+
+// Summary returns a line with the station's identifier, location and products
+func (s *Station) Summary() string {
+	return fmt.Sprintf("%-4s %-30s %-2s %-2s %9.4f %10.4f %6.0fm %s",
+		s.StationId, s.Site, s.State, s.Country, s.Latitude, s.Longitude, s.ElevationM, strings.Join(s.SiteType, " "))
+}
+
+// ToSummary returns one line per station with its identifier, location and products
+func (r *Response) ToSummary() (s []string) {
+	for _, st := range r.Data.Stations {
+		s = append(s, st.Summary())
+	}
+	return
+}
+
+func (r *Response) ToJson() (s string, err error) {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
+
+func (r *Response) ToJsonIndented() (s string, err error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	s = string(bytes)
+	return
+}
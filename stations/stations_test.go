@@ -0,0 +1,74 @@
+package stations
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeXML(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/adds_stations.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Response
+	if err := xml.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Version != "1.0" || r.RequestIndex != 84531002 || r.DataSource.Name != "stations" || r.Request.Type != "retrieve" {
+		t.Errorf("response version/request index/data source/request = %s/%d/%s/%s",
+			r.Version, r.RequestIndex, r.DataSource.Name, r.Request.Type)
+	}
+	if r.Data.NumResults != 4 || len(r.Data.Stations) != 4 {
+		t.Fatalf("got %d stations (num_results %d), want 4", len(r.Data.Stations), r.Data.NumResults)
+	}
+
+	den := r.Data.Stations[0]
+	if den.StationId != "KDEN" || den.WmoId != "72565" || den.Latitude != 39.85 || den.Longitude != -104.65 ||
+		den.ElevationM != 1640 || den.Site != "DENVER (DIA)" || den.State != "CO" || den.Country != "US" {
+		t.Errorf("KDEN = %+v", den)
+	}
+
+	tests := []struct {
+		station  string
+		siteType SiteType
+		metar    bool
+		taf      bool
+		raob     bool
+	}{
+		{"KDEN", SiteType{"METAR", "TAF"}, true, true, false},
+		{"KOAX", SiteType{"rawinsonde", "NEXRAD", "WFO_office"}, false, false, true},
+		{"CYVR", SiteType{"METAR", "TAF", "SYNOPS"}, true, true, false},
+		{"K0V1", nil, false, false, false},
+	}
+	for i, tt := range tests {
+		s := r.Data.Stations[i]
+		if s.StationId != tt.station {
+			t.Fatalf("station %d = %s, want %s", i, s.StationId, tt.station)
+		}
+		if !reflect.DeepEqual(s.SiteType, tt.siteType) {
+			t.Errorf("%s site_type = %q, want %q", s.StationId, s.SiteType, tt.siteType)
+		}
+		if s.HasMetar() != tt.metar || s.HasTaf() != tt.taf || s.HasRawinsonde() != tt.raob {
+			t.Errorf("%s HasMetar/HasTaf/HasRawinsonde = %v/%v/%v, want %v/%v/%v", s.StationId,
+				s.HasMetar(), s.HasTaf(), s.HasRawinsonde(), tt.metar, tt.taf, tt.raob)
+		}
+	}
+	if !r.Data.Stations[1].Has("nexrad") {
+		t.Error("Has(nexrad) = false for KOAX, want products compared case-insensitively")
+	}
+}
+
+func TestSiteTypeKeepsFollowingElements(t *testing.T) {
+	const doc = `<Station><site_type><METAR><ignored>x</ignored></METAR><TAF/></site_type><state>CO</state></Station>`
+
+	var s Station
+	if err := xml.Unmarshal([]byte(doc), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.SiteType, SiteType{"METAR", "TAF"}) || s.State != "CO" {
+		t.Errorf("site_type/state = %q/%q, want [METAR TAF]/CO", s.SiteType, s.State)
+	}
+}
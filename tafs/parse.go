@@ -0,0 +1,327 @@
+package tafs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/theperiscope/avwx/internal/decode"
+)
+
+// ParseError is returned by Parse, together with the decoded TAF, for groups it did not recognize. They are also kept
+// in the NotDecoded field of their forecast.
+type ParseError struct {
+	RawText string
+	Groups  []string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("unrecognized group(s) %s in TAF %q", strings.Join(e.Groups, " "), e.RawText)
+}
+
+var (
+	stationPattern     = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	issueTimePattern   = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	periodPattern      = regexp.MustCompile(`^(\d{2})(\d{2})/(\d{2})(\d{2})$`)
+	oldPeriodPattern   = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})$`) // DDHHHH, used before November 2008
+	fromPattern        = regexp.MustCompile(`^FM(\d{2})(\d{2})(\d{2})$`)
+	oldFromPattern     = regexp.MustCompile(`^FM(\d{2})(\d{2})$`)               // FMHHMM
+	oldChangePattern   = regexp.MustCompile(`^([01]\d|2[0-4])([01]\d|2[0-4])$`) // HHHH, of BECMG, TEMPO and PROB groups
+	probPattern        = regexp.MustCompile(`^PROB(\d{2})$`)
+	windShearPattern   = regexp.MustCompile(`^WS(\d{3})/(\d{3})(\d{2,3})KT$`)
+	temperaturePattern = regexp.MustCompile(`^T([XN])(M?\d{2})/(\d{2})(\d{2})Z$`)
+	qnhPattern         = regexp.MustCompile(`^QNH(\d{4})INS$`)
+	layerPattern       = regexp.MustCompile(`^([56])(\d)(\d{3})(\d)$`) // 6IhhhT icing and 5BhhhT turbulence
+)
+
+// Parse decodes a raw TAF into a Taf, with the issue time taken to be in the current month. See ParseAt.
+func Parse(raw string) (*Taf, error) {
+	return ParseAt(raw, time.Now())
+}
+
+// ParseAt decodes a raw TAF into a Taf with one Forecast per change group, following the ADDS conventions: the
+// initial forecast and FM and BECMG groups last until the next FM or BECMG group, BECMG groups have the end of their
+// transition in TimeBecoming, and TEMPO and PROB groups last for their own period. Times only give the day of the
+// month, the issue time is placed in the month of ref or, if that would be more than a day after ref, the month before.
+//
+// TX and TN temperature groups forecast for the whole TAF and are kept with the initial forecast, wherever they appear.
+// AMD, COR, NIL and CNL and any remarks are kept in Remarks. Groups that are not recognized are returned in a
+// *ParseError together with the TAF decoded from the other groups; a TAF without station or valid period is an error.
+func ParseAt(raw string, ref time.Time) (*Taf, error) {
+	t := &Taf{RawText: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "="))}
+	groups := strings.Fields(strings.ToUpper(t.RawText))
+
+	var remarks []string
+	if len(groups) > 0 && groups[0] == "TAF" {
+		groups = groups[1:]
+	}
+	for len(groups) > 0 && (groups[0] == "AMD" || groups[0] == "COR") {
+		remarks = append(remarks, groups[0])
+		groups = groups[1:]
+	}
+
+	if len(groups) == 0 || !stationPattern.MatchString(groups[0]) {
+		return nil, fmt.Errorf("TAF %q does not start with a station identifier", raw)
+	}
+	t.StationId = groups[0]
+	groups = groups[1:]
+
+	issue := ref
+	if len(groups) > 0 {
+		if s := issueTimePattern.FindStringSubmatch(groups[0]); s != nil {
+			t.IssueTime = decode.DayTime(int(decode.Atoi(s[1])), int(decode.Atoi(s[2])), int(decode.Atoi(s[3])), ref)
+			issue = t.IssueTime
+			groups = groups[1:]
+		}
+	}
+
+	if len(groups) > 0 && groups[0] == "NIL" {
+		t.Remarks = strings.Join(append(remarks, "NIL"), " ")
+		return t, nil
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("TAF %q has no valid period", raw)
+	}
+	from, to, ok := parsePeriod(groups[0], issue)
+	if !ok {
+		return nil, fmt.Errorf("TAF %q has no valid period", raw)
+	}
+	t.ValidTimeFrom, t.ValidTimeTo = from, to
+	oldFormat := oldPeriodPattern.MatchString(groups[0])
+	groups = groups[1:]
+
+	if len(groups) > 0 && groups[0] == "CNL" {
+		t.Remarks = strings.Join(append(remarks, "CNL"), " ")
+		return t, nil
+	}
+
+	p := parser{taf: t, issue: issue, oldFormat: oldFormat}
+	p.start(Forecast{FcstTimeFrom: from}, true)
+
+	for i := 0; i < len(groups); i++ {
+		g := groups[i]
+
+		switch {
+		case g == "RMK":
+			remarks = append(remarks, strings.Join(groups[i+1:], " "))
+			i = len(groups)
+			continue
+		case g == "AMD":
+			// the US amendment notes like AMD NOT SKED AFT 1812, which continue a leading AMD rather than repeat it
+			note := groups[i:]
+			if len(remarks) > 0 && remarks[len(remarks)-1] == "AMD" {
+				note = note[1:]
+			}
+			remarks = append(remarks, strings.Join(note, " "))
+			i = len(groups)
+			continue
+		case fromPattern.MatchString(g):
+			s := fromPattern.FindStringSubmatch(g)
+			at := periodTime(int(decode.Atoi(s[1])), int(decode.Atoi(s[2])), issue).Add(time.Duration(decode.Atoi(s[3])) * time.Minute)
+			p.start(Forecast{FcstTimeFrom: at, ChangeIndicator: "FM"}, true)
+			continue
+		case oldFromPattern.MatchString(g):
+			s := oldFromPattern.FindStringSubmatch(g)
+			at := p.hourAfter(int(decode.Atoi(s[1]))).Add(time.Duration(decode.Atoi(s[2])) * time.Minute)
+			p.start(Forecast{FcstTimeFrom: at, ChangeIndicator: "FM"}, true)
+			continue
+		case g == "BECMG" && i+1 < len(groups):
+			if from, to, ok := p.changePeriod(groups[i+1]); ok {
+				p.start(Forecast{FcstTimeFrom: from, TimeBecoming: to, ChangeIndicator: "BECMG"}, true)
+				i++
+				continue
+			}
+		case g == "TEMPO" && i+1 < len(groups):
+			if from, to, ok := p.changePeriod(groups[i+1]); ok {
+				p.start(Forecast{FcstTimeFrom: from, FcstTimeTo: to, ChangeIndicator: "TEMPO"}, false)
+				i++
+				continue
+			}
+		case probPattern.MatchString(g) && i+1 < len(groups):
+			f := Forecast{ChangeIndicator: "PROB", Probability: decode.Atoi(probPattern.FindStringSubmatch(g)[1])}
+			j := i + 1
+			if groups[j] == "TEMPO" && j+1 < len(groups) {
+				f.ChangeIndicator = "TEMPO"
+				j++
+			}
+			if from, to, ok := p.changePeriod(groups[j]); ok {
+				f.FcstTimeFrom, f.FcstTimeTo = from, to
+				p.start(f, false)
+				i = j
+				continue
+			}
+		}
+
+		p.element(groups, &i)
+	}
+
+	p.finish(t.ValidTimeTo)
+	t.Remarks = strings.Join(remarks, " ")
+
+	if len(p.unknown) > 0 {
+		return t, &ParseError{RawText: t.RawText, Groups: p.unknown}
+	}
+	return t, nil
+}
+
+// parser collects the forecasts of a TAF while its groups are decoded
+type parser struct {
+	taf          *Taf
+	issue        time.Time
+	oldFormat    bool // the TAF has a DDHHHH valid period and HHHH change periods
+	lastBase     int  // index of the last forecast lasting until the next FM or BECMG group
+	weather      []string
+	temperatures []Temperature // TX and TN groups, which belong to the TAF rather than the forecast they follow
+	unknown      []string
+}
+
+// start begins a new forecast, ending the previous base forecast if f is one
+func (p *parser) start(f Forecast, base bool) {
+	p.flush()
+
+	if base {
+		if len(p.taf.Forecast) > 0 {
+			p.taf.Forecast[p.lastBase].FcstTimeTo = f.FcstTimeFrom
+		}
+		p.lastBase = len(p.taf.Forecast)
+	}
+	p.taf.Forecast = append(p.taf.Forecast, f)
+}
+
+// flush stores the weather groups collected for the current forecast
+func (p *parser) flush() {
+	if len(p.taf.Forecast) == 0 {
+		return
+	}
+	f := &p.taf.Forecast[len(p.taf.Forecast)-1]
+	f.WxString = strings.Join(p.weather, " ")
+	p.weather = nil
+}
+
+// finish ends the last base forecast at the end of the valid period and adds the temperatures to the initial forecast
+func (p *parser) finish(end time.Time) {
+	p.flush()
+	if len(p.taf.Forecast) > 0 {
+		p.taf.Forecast[p.lastBase].FcstTimeTo = end
+		p.taf.Forecast[0].Temperature = append(p.taf.Forecast[0].Temperature, p.temperatures...)
+	}
+}
+
+// hourAfter returns the first time at hour at or after the start of the valid period, for FMHHMM groups
+func (p *parser) hourAfter(hour int) time.Time {
+	from := p.taf.ValidTimeFrom
+	t := time.Date(from.Year(), from.Month(), from.Day(), hour, 0, 0, 0, time.UTC)
+	if t.Before(from) {
+		t = t.Add(24 * time.Hour)
+	}
+	return t
+}
+
+// changePeriod decodes the period of a BECMG, TEMPO or PROB group. Older TAFs give it as HHHH, the first time at
+// each hour at or after the start of the valid period and of the change, like their FMHHMM groups.
+func (p *parser) changePeriod(g string) (from, to time.Time, ok bool) {
+	if s := oldChangePattern.FindStringSubmatch(g); s != nil && p.oldFormat {
+		from = p.hourAfter(int(decode.Atoi(s[1])))
+		to = time.Date(from.Year(), from.Month(), from.Day(), int(decode.Atoi(s[2])), 0, 0, 0, time.UTC)
+		if !to.After(from) {
+			to = to.Add(24 * time.Hour)
+		}
+		return from, to, true
+	}
+	return parsePeriod(g, p.issue)
+}
+
+// element decodes the forecast element at groups[*i] into the current forecast, advancing *i past groups it used
+func (p *parser) element(groups []string, i *int) {
+	f := &p.taf.Forecast[len(p.taf.Forecast)-1]
+	g := groups[*i]
+
+	if w, ok := decode.ParseWind(g); ok {
+		f.WindDirDegrees, f.WindSpeedKt, f.WindGustKt = w.DirDegrees, w.SpeedKt, w.GustKt
+		return
+	}
+	if mi, n, ok := decode.ParseVisibility(groups, *i); ok {
+		f.VisibilityStatuteMi = mi
+		*i += n - 1
+		return
+	}
+	if sky, ok := decode.ParseSky(g); ok {
+		f.SkyCondition = append(f.SkyCondition, SkyCondition{SkyCover: sky.Cover, CloudBaseFtAGL: sky.BaseFtAGL, CloudType: sky.CloudType})
+		return
+	}
+	if vv, ok := decode.ParseVertVis(g); ok {
+		// ADDS reports an indefinite ceiling as an obscured sky with the vertical visibility
+		f.VertVisFt = vv
+		f.SkyCondition = append(f.SkyCondition, SkyCondition{SkyCover: "OVX"})
+		return
+	}
+
+	switch {
+	case g == "NSW" || decode.IsWeather(g):
+		p.weather = append(p.weather, g)
+	case windShearPattern.MatchString(g):
+		s := windShearPattern.FindStringSubmatch(g)
+		f.WindShearHgtFtAgl = decode.Atoi(s[1]) * 100
+		f.WindShearDirDegrees = decode.Atoi(s[2])
+		f.WindShearSpeedKt = float64(decode.Atoi(s[3]))
+	case temperaturePattern.MatchString(g):
+		s := temperaturePattern.FindStringSubmatch(g)
+		c := float64(decode.Atoi(strings.TrimPrefix(s[2], "M")))
+		if strings.HasPrefix(s[2], "M") {
+			c = -c
+		}
+		temp := Temperature{ValidTime: periodTime(int(decode.Atoi(s[3])), int(decode.Atoi(s[4])), p.issue)}
+		if s[1] == "X" {
+			temp.MaxTempC = &c
+		} else {
+			temp.MinTempC = &c
+		}
+		p.temperatures = append(p.temperatures, temp)
+	case layerPattern.MatchString(g):
+		// the layer starts at hhh hundred feet and is T thousand feet thick
+		s := layerPattern.FindStringSubmatch(g)
+		min := decode.Atoi(s[3]) * 100
+		max := min + decode.Atoi(s[4])*1000
+		if s[1] == "6" {
+			f.IcingCondition = append(f.IcingCondition, IcingCondition{Intensity: s[2], MinAltFtAGL: min, MaxAltFtAGL: max})
+		} else {
+			f.TurbulenceCondition = append(f.TurbulenceCondition, TurbulenceCondition{Intensity: s[2], MinAltFtAGL: min, MaxAltFtAGL: max})
+		}
+	case qnhPattern.MatchString(g):
+		// the altimeter setting of military TAFs
+		f.AltimInHg = float64(decode.Atoi(qnhPattern.FindStringSubmatch(g)[1])) / 100
+	default:
+		f.NotDecoded = strings.TrimSpace(f.NotDecoded + " " + g)
+		p.unknown = append(p.unknown, g)
+	}
+}
+
+// parsePeriod decodes DDHH/DDHH and, for the valid period of older TAFs, DDHHHH periods
+func parsePeriod(g string, issue time.Time) (from, to time.Time, ok bool) {
+	if s := periodPattern.FindStringSubmatch(g); s != nil {
+		from = periodTime(int(decode.Atoi(s[1])), int(decode.Atoi(s[2])), issue)
+		to = periodTime(int(decode.Atoi(s[3])), int(decode.Atoi(s[4])), issue)
+		return from, to, !to.Before(from)
+	}
+	if s := oldPeriodPattern.FindStringSubmatch(g); s != nil {
+		from = periodTime(int(decode.Atoi(s[1])), int(decode.Atoi(s[2])), issue)
+		to = time.Date(from.Year(), from.Month(), from.Day(), int(decode.Atoi(s[3])), 0, 0, 0, time.UTC)
+		if !to.After(from) {
+			to = to.Add(24 * time.Hour)
+		}
+		return from, to, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// periodTime returns the time at day and hour, hour 24 being the midnight ending day, in the month of issue or, for
+// periods reaching into the next month, the month after
+func periodTime(day, hour int, issue time.Time) time.Time {
+	t := time.Date(issue.Year(), issue.Month(), day, hour, 0, 0, 0, time.UTC)
+	if t.Before(issue.Add(-24 * time.Hour)) {
+		t = time.Date(issue.Year(), issue.Month()+1, day, hour, 0, 0, 0, time.UTC)
+	}
+	return t
+}
@@ -0,0 +1,228 @@
+package tafs
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var testRef = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func at(day, hour int) time.Time {
+	return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC)
+}
+
+// period is the change indicator and the times of a forecast
+type period struct {
+	change   string
+	from, to time.Time
+}
+
+func periods(t *Taf) (p []period) {
+	for _, f := range t.Forecast {
+		p = append(p, period{f.ChangeIndicator, f.FcstTimeFrom, f.FcstTimeTo})
+	}
+	return
+}
+
+// temperatures returns the TX/TN groups of a forecast in a form like X15/1814
+func temperatures(f *Forecast) (s []string) {
+	for _, temp := range f.Temperature {
+		switch {
+		case temp.MaxTempC != nil:
+			s = append(s, "X"+strconv.FormatFloat(*temp.MaxTempC, 'f', -1, 64)+"/"+temp.ValidTime.Format("0215"))
+		case temp.MinTempC != nil:
+			s = append(s, "N"+strconv.FormatFloat(*temp.MinTempC, 'f', -1, 64)+"/"+temp.ValidTime.Format("0215"))
+		}
+	}
+	return
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		ref     time.Time
+		periods []period
+		remarks string
+		check   func(t *testing.T, taf *Taf)
+	}{
+		{
+			name: "ICAO with BECMG, PROB TEMPO and temperatures",
+			raw:  "TAF EGLL 181100Z 1812/1918 24012KT 9999 SCT030 BECMG 1820/1823 30008KT PROB30 TEMPO 1900/1906 4000 SHRA BKN012 TX15/1814Z TNM02/1906Z",
+			ref:  testRef,
+			periods: []period{
+				{"", at(18, 12), at(18, 20)},
+				{"BECMG", at(18, 20), at(19, 18)},
+				{"TEMPO", at(19, 0), at(19, 6)},
+			},
+			check: func(t *testing.T, taf *Taf) {
+				if !taf.IssueTime.Equal(time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC)) {
+					t.Errorf("issue time = %v", taf.IssueTime)
+				}
+				if becmg := taf.Forecast[1]; !becmg.TimeBecoming.Equal(at(18, 23)) || becmg.WindDirDegrees != 300 {
+					t.Errorf("BECMG becoming/wind = %v/%d", becmg.TimeBecoming, becmg.WindDirDegrees)
+				}
+				tempo := taf.Forecast[2]
+				if tempo.Probability != 30 || tempo.WxString != "SHRA" || tempo.VisibilityStatuteMi != 2.49 {
+					t.Errorf("PROB30 TEMPO probability/weather/visibility = %d/%s/%v", tempo.Probability, tempo.WxString, tempo.VisibilityStatuteMi)
+				}
+				if got, want := temperatures(&taf.Forecast[0]), []string{"X15/1814", "N-2/1906"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("base temperatures = %q, want %q", got, want)
+				}
+				if len(tempo.Temperature) != 0 {
+					t.Errorf("TEMPO temperatures = %+v, want none", tempo.Temperature)
+				}
+			},
+		},
+		{
+			name: "ICAO temperatures after a PROB TEMPO group across the month end",
+			raw:  "TAF LFPG 311100Z 3112/0118 22010KT CAVOK PROB40 TEMPO 0114/0118 4000 SHRA TX15/0114Z TNM02/0106Z",
+			ref:  time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC),
+			periods: []period{
+				{"", at(31, 12), time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)},
+				{"TEMPO", time.Date(2026, 11, 1, 14, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)},
+			},
+			check: func(t *testing.T, taf *Taf) {
+				if got, want := temperatures(&taf.Forecast[0]), []string{"X15/0114", "N-2/0106"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("base temperatures = %q, want %q", got, want)
+				}
+				if tempo := taf.Forecast[1]; len(tempo.Temperature) != 0 || tempo.Probability != 40 {
+					t.Errorf("PROB40 TEMPO temperatures/probability = %+v/%d", tempo.Temperature, tempo.Probability)
+				}
+			},
+		},
+		{
+			name: "US with FM, TEMPO, wind shear and amendment note",
+			raw:  "TAF AMD KDEN 181120Z 1812/1918 VRB05KT P6SM SCT080 FM181800 27015G25KT P6SM VCSH BKN060CB TEMPO 1820/1824 3SM TSRA BKN040CB FM190300 36010KT P6SM SKC WS020/18040KT AMD NOT SKED AFT 1900",
+			ref:  testRef,
+			periods: []period{
+				{"", at(18, 12), at(18, 18)},
+				{"FM", at(18, 18), at(19, 3)},
+				{"TEMPO", at(18, 20), at(19, 0)},
+				{"FM", at(19, 3), at(19, 18)},
+			},
+			remarks: "AMD NOT SKED AFT 1900",
+			check: func(t *testing.T, taf *Taf) {
+				from := taf.Forecast[1]
+				if from.WindGustKt != 25 || from.WxString != "VCSH" || len(from.SkyCondition) != 1 || from.SkyCondition[0].CloudType != "CB" {
+					t.Errorf("FM gust/weather/sky = %d/%s/%+v", from.WindGustKt, from.WxString, from.SkyCondition)
+				}
+				last := taf.Forecast[3]
+				if last.WindShearHgtFtAgl != 2000 || last.WindShearDirDegrees != 180 || last.WindShearSpeedKt != 40 {
+					t.Errorf("wind shear = %d/%d/%v, want 2000/180/40", last.WindShearHgtFtAgl, last.WindShearDirDegrees, last.WindShearSpeedKt)
+				}
+			},
+		},
+		{
+			name: "US amendment note without a leading AMD",
+			raw:  "TAF KORD 181120Z 1812/1918 27012KT P6SM FEW250 AMD NOT SKED",
+			ref:  testRef,
+			periods: []period{
+				{"", at(18, 12), at(19, 18)},
+			},
+			remarks: "AMD NOT SKED",
+		},
+		{
+			name: "military with turbulence, icing and QNH",
+			raw:  "TAF KBLV 181100Z 1812/1918 18010KT 9999 BKN030 520106 620304 QNH2992INS TX24/1821Z TN08/1912Z",
+			ref:  testRef,
+			periods: []period{
+				{"", at(18, 12), at(19, 18)},
+			},
+			check: func(t *testing.T, taf *Taf) {
+				f := taf.Forecast[0]
+				if want := []TurbulenceCondition{{Intensity: "2", MinAltFtAGL: 1000, MaxAltFtAGL: 7000}}; !reflect.DeepEqual(f.TurbulenceCondition, want) {
+					t.Errorf("turbulence = %+v, want %+v", f.TurbulenceCondition, want)
+				}
+				if want := []IcingCondition{{Intensity: "2", MinAltFtAGL: 3000, MaxAltFtAGL: 7000}}; !reflect.DeepEqual(f.IcingCondition, want) {
+					t.Errorf("icing = %+v, want %+v", f.IcingCondition, want)
+				}
+				if f.AltimInHg != 29.92 || len(f.Temperature) != 2 {
+					t.Errorf("altimeter/temperatures = %v/%d", f.AltimInHg, len(f.Temperature))
+				}
+			},
+		},
+		{
+			name: "old DDHHHH period and FMHHMM",
+			raw:  "KJFK 181130Z 181212 18010KT P6SM BKN250 FM2200 20015KT 5SM BR",
+			ref:  testRef,
+			periods: []period{
+				{"", at(18, 12), at(18, 22)},
+				{"FM", at(18, 22), at(19, 12)},
+			},
+		},
+		{
+			name: "old HHHH change periods",
+			raw:  "TAF KORD 181130Z 181212 27010KT P6SM SCT040 TEMPO 1416 -SHRA BKN015 BECMG 2022 32012KT PROB30 0206 TSRA",
+			ref:  testRef,
+			periods: []period{
+				{"", at(18, 12), at(18, 20)},
+				{"TEMPO", at(18, 14), at(18, 16)},
+				{"BECMG", at(18, 20), at(19, 12)},
+				{"PROB", at(19, 2), at(19, 6)},
+			},
+			check: func(t *testing.T, taf *Taf) {
+				base, tempo := taf.Forecast[0], taf.Forecast[1]
+				if base.VisibilityStatuteMi != 6 || base.WxString != "" || len(base.SkyCondition) != 1 {
+					t.Errorf("initial forecast visibility/weather/sky = %v/%q/%+v, want it untouched by the TEMPO group", base.VisibilityStatuteMi, base.WxString, base.SkyCondition)
+				}
+				if tempo.WxString != "-SHRA" || !reflect.DeepEqual(tempo.SkyCondition, []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 1500}}) {
+					t.Errorf("TEMPO weather/sky = %q/%+v, want -SHRA/BKN015", tempo.WxString, tempo.SkyCondition)
+				}
+				if !taf.Forecast[2].TimeBecoming.Equal(at(18, 22)) || taf.Forecast[3].Probability != 30 {
+					t.Errorf("BECMG until %v and PROB%d, want 2200 and PROB30", taf.Forecast[2].TimeBecoming, taf.Forecast[3].Probability)
+				}
+			},
+		},
+		{
+			name:    "NIL",
+			raw:     "TAF AMD KSEA 181140Z NIL",
+			ref:     testRef,
+			remarks: "AMD NIL",
+		},
+		{
+			name:    "cancelled",
+			raw:     "TAF AMD KSEA 181140Z 1812/1912 CNL=",
+			ref:     testRef,
+			remarks: "AMD CNL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taf, err := ParseAt(tt.raw, tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := periods(taf); !reflect.DeepEqual(got, tt.periods) {
+				t.Errorf("periods = %v, want %v", got, tt.periods)
+			}
+			if taf.Remarks != tt.remarks {
+				t.Errorf("remarks = %q, want %q", taf.Remarks, tt.remarks)
+			}
+			if tt.check != nil && len(taf.Forecast) == len(tt.periods) {
+				tt.check(t, taf)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	taf, err := ParseAt("TAF KORD 181120Z 1812/1918 27012KT P6SM XYZZY FEW250", testRef)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !reflect.DeepEqual(parseErr.Groups, []string{"XYZZY"}) {
+		t.Fatalf("err = %v, want a ParseError for XYZZY", err)
+	}
+	if taf == nil || taf.Forecast[0].NotDecoded != "XYZZY" || len(taf.Forecast[0].SkyCondition) != 1 {
+		t.Errorf("TAF = %+v, want the other groups decoded and XYZZY in NotDecoded", taf)
+	}
+
+	for _, raw := range []string{"", "TAF", "TAF KORD 181120Z", "TAF KORD 181120Z 27012KT"} {
+		if _, err := ParseAt(raw, testRef); err == nil {
+			t.Errorf("ParseAt(%q) succeeded, want an error", raw)
+		}
+	}
+}
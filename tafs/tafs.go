@@ -1,9 +1,14 @@
 package tafs
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"strconv"
 	"time"
+
+	"github.com/theperiscope/avwx/wx"
 )
 
 type Response struct {
@@ -39,32 +44,53 @@ type SkyCondition struct {
 	CloudType      string   `xml:"cloud_type,attr"`
 }
 
+// TurbulenceCondition is a forecast turbulence layer, Intensity is the ICAO turbulence code
+type TurbulenceCondition struct {
+	XMLName     xml.Name `xml:"turbulence_condition" json:"-"`
+	Intensity   string   `xml:"turbulence_intensity,attr"`
+	MinAltFtAGL int32    `xml:"turbulence_min_alt_ft_agl,attr"`
+	MaxAltFtAGL int32    `xml:"turbulence_max_alt_ft_agl,attr"`
+}
+
+// IcingCondition is a forecast icing layer, Intensity is the ICAO icing code
+type IcingCondition struct {
+	XMLName     xml.Name `xml:"icing_condition" json:"-"`
+	Intensity   string   `xml:"icing_intensity,attr"`
+	MinAltFtAGL int32    `xml:"icing_min_alt_ft_agl,attr"`
+	MaxAltFtAGL int32    `xml:"icing_max_alt_ft_agl,attr"`
+}
+
+// Temperature is a forecast temperature, from the TX and TN groups
+type Temperature struct {
+	XMLName   xml.Name  `xml:"temperature" json:"-"`
+	ValidTime time.Time `xml:"valid_time"`
+	SfcTempC  *float64  `xml:"sfc_temp_c" json:",omitempty"`
+	MaxTempC  *float64  `xml:"max_temp_c" json:",omitempty"`
+	MinTempC  *float64  `xml:"min_temp_c" json:",omitempty"`
+}
+
 type Forecast struct {
-	XMLName             xml.Name  `xml:"forecast" json:"-"`
-	FcstTimeFrom        time.Time `xml:"fcst_time_from"`
-	FcstTimeTo          time.Time `xml:"fcst_time_to"`
-	ChangeIndicator     string    `xml:"change_indicator"`
-	TimeBecoming        time.Time `xml:"time_becoming"`
-	Probability         int32     `xml:"probability"`
-	WindDirDegrees      int32     `xml:"wind_dir_degrees"`
-	WindSpeedKt         int32     `xml:"wind_speed_kt"`
-	WindGustKt          int32     `xml:"wind_gust_kt"`
-	WindShearHgtFtAgl   int32     `xml:"wind_shear_hgt_ft_agl"`
-	WindShearDirDegrees int32     `xml:"wind_shear_dir_degrees"`
-	WindShearSpeedKt    float64   `xml:"wind_shear_speed_kt"`
-	VisibilityStatuteMi float64   `xml:"visibility_statute_mi"`
-	AltimInHg           int32     `xml:"altim_in_hg"`
-	VertVisFt           string    `xml:"vert_vis_ft"`
-	WxString            string    `xml:"wx_string"`
-	NotDecoded          string    `xml:"not_decoded"`
-	SkyCondition        string    `xml:"sky_condition"`
-	TurbulenceCondition string    `xml:"turbulence_condition"`
-	IcingCondition      string    `xml:"icing_condition"`
-	Temperature         []int32   `xml:"temperature"`
-	ValidTime           string    `xml:"valid_time"`
-	SfcTempC            float64   `xml:"sfc_temp_c"`
-	MaxTempC            float64   `xml:"max_temp_c"`
-	MinTempC            float64   `xml:"min_temp_c"`
+	XMLName             xml.Name              `xml:"forecast" json:"-"`
+	FcstTimeFrom        time.Time             `xml:"fcst_time_from"`
+	FcstTimeTo          time.Time             `xml:"fcst_time_to"`
+	ChangeIndicator     string                `xml:"change_indicator"`
+	TimeBecoming        time.Time             `xml:"time_becoming"`
+	Probability         int32                 `xml:"probability"`
+	WindDirDegrees      int32                 `xml:"wind_dir_degrees"`
+	WindSpeedKt         int32                 `xml:"wind_speed_kt"`
+	WindGustKt          int32                 `xml:"wind_gust_kt"`
+	WindShearHgtFtAgl   int32                 `xml:"wind_shear_hgt_ft_agl"`
+	WindShearDirDegrees int32                 `xml:"wind_shear_dir_degrees"`
+	WindShearSpeedKt    float64               `xml:"wind_shear_speed_kt"`
+	VisibilityStatuteMi float64               `xml:"visibility_statute_mi"`
+	AltimInHg           float64               `xml:"altim_in_hg"`
+	VertVisFt           int32                 `xml:"vert_vis_ft"`
+	WxString            string                `xml:"wx_string"`
+	NotDecoded          string                `xml:"not_decoded"`
+	SkyCondition        []SkyCondition        `xml:"sky_condition"`
+	TurbulenceCondition []TurbulenceCondition `xml:"turbulence_condition"`
+	IcingCondition      []IcingCondition      `xml:"icing_condition"`
+	Temperature         []Temperature         `xml:"temperature"`
 }
 
 type Taf struct {
@@ -108,3 +134,117 @@ func (r *Response) ToJsonIndented() (s string, err error) {
 	s = string(b)
 	return
 }
+
+// csvSkyConditions and csvLayers are the number of sky condition and of turbulence and icing layer columns written
+// by ToCsv
+const (
+	csvSkyConditions = 4
+	csvLayers        = 2
+)
+
+// CsvHeader returns the columns written by ToCsv, in order
+func CsvHeader() []string {
+	h := []string{"station_id", "issue_time", "valid_time_from", "valid_time_to", "latitude", "longitude",
+		"elevation_m", "fcst_time_from", "fcst_time_to", "change_indicator", "time_becoming", "probability",
+		"wind_dir_degrees", "wind_speed_kt", "wind_gust_kt", "wind_shear_hgt_ft_agl", "wind_shear_dir_degrees",
+		"wind_shear_speed_kt", "visibility_statute_mi", "altim_in_hg", "vert_vis_ft", "wx_string", "not_decoded"}
+	for n := 1; n <= csvSkyConditions; n++ {
+		s := strconv.Itoa(n)
+		h = append(h, "sky_cover_"+s, "cloud_base_ft_agl_"+s, "cloud_type_"+s)
+	}
+	for n := 1; n <= csvLayers; n++ {
+		s := strconv.Itoa(n)
+		h = append(h, "turbulence_intensity_"+s, "turbulence_min_alt_ft_agl_"+s, "turbulence_max_alt_ft_agl_"+s)
+	}
+	for n := 1; n <= csvLayers; n++ {
+		s := strconv.Itoa(n)
+		h = append(h, "icing_intensity_"+s, "icing_min_alt_ft_agl_"+s, "icing_max_alt_ft_agl_"+s)
+	}
+	return append(h, "max_temp_c", "max_temp_time", "min_temp_c", "min_temp_time")
+}
+
+// CsvRecords returns one record per forecast group in the order of CsvHeader, repeating the TAF's own values. A TAF
+// without forecast groups, e.g. a NIL or cancelled one, gets a single record with empty forecast columns.
+func (t *Taf) CsvRecords() (records [][]string) {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	i := func(v int32) string { return strconv.FormatInt(int64(v), 10) }
+	tm := func(v time.Time) string {
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	}
+
+	taf := []string{t.StationId, tm(t.IssueTime), tm(t.ValidTimeFrom), tm(t.ValidTimeTo),
+		f(t.Latitude), f(t.Longitude), f(t.ElevationM)}
+	if len(t.Forecast) == 0 {
+		return [][]string{append(taf, make([]string, len(CsvHeader())-len(taf))...)}
+	}
+
+	for _, fc := range t.Forecast {
+		rec := append(append([]string{}, taf...), tm(fc.FcstTimeFrom), tm(fc.FcstTimeTo), fc.ChangeIndicator,
+			tm(fc.TimeBecoming), i(fc.Probability), i(fc.WindDirDegrees), i(fc.WindSpeedKt), i(fc.WindGustKt),
+			i(fc.WindShearHgtFtAgl), i(fc.WindShearDirDegrees), f(fc.WindShearSpeedKt), f(fc.VisibilityStatuteMi),
+			f(fc.AltimInHg), i(fc.VertVisFt), fc.WxString, fc.NotDecoded)
+		for n := 0; n < csvSkyConditions; n++ {
+			if n < len(fc.SkyCondition) {
+				s := fc.SkyCondition[n]
+				rec = append(rec, s.SkyCover, i(s.CloudBaseFtAGL), s.CloudType)
+			} else {
+				rec = append(rec, "", "", "")
+			}
+		}
+		for n := 0; n < csvLayers; n++ {
+			if n < len(fc.TurbulenceCondition) {
+				l := fc.TurbulenceCondition[n]
+				rec = append(rec, l.Intensity, i(l.MinAltFtAGL), i(l.MaxAltFtAGL))
+			} else {
+				rec = append(rec, "", "", "")
+			}
+		}
+		for n := 0; n < csvLayers; n++ {
+			if n < len(fc.IcingCondition) {
+				l := fc.IcingCondition[n]
+				rec = append(rec, l.Intensity, i(l.MinAltFtAGL), i(l.MaxAltFtAGL))
+			} else {
+				rec = append(rec, "", "", "")
+			}
+		}
+
+		var maxTemp, maxTime, minTemp, minTime string
+		for _, temp := range fc.Temperature {
+			if temp.MaxTempC != nil {
+				maxTemp, maxTime = f(*temp.MaxTempC), tm(temp.ValidTime)
+			}
+			if temp.MinTempC != nil {
+				minTemp, minTime = f(*temp.MinTempC), tm(temp.ValidTime)
+			}
+		}
+		records = append(records, append(rec, maxTemp, maxTime, minTemp, minTime))
+	}
+	return
+}
+
+// ToCsv returns the TAFs as CSV with a header row and one row per forecast group
+func (r *Response) ToCsv() (s string, err error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	w.Write(CsvHeader())
+	for i := range r.Data.Tafs {
+		w.WriteAll(r.Data.Tafs[i].CsvRecords())
+	}
+
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return "", err
+	}
+
+	s = b.String()
+	return
+}
+
+// WeatherPhenomena decodes WxString
+func (f *Forecast) WeatherPhenomena() ([]wx.Phenomenon, error) {
+	return wx.Parse(f.WxString)
+}
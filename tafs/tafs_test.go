@@ -0,0 +1,143 @@
+package tafs
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeXML(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/adds_tafs.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Response
+	if err := xml.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Version != "1.2" || r.RequestIndex != 51178733 || r.DataSource.Name != "tafs" || r.TimeTakenMs != 6 {
+		t.Errorf("response version/request index/data source/time = %s/%d/%s/%d", r.Version, r.RequestIndex, r.DataSource.Name, r.TimeTakenMs)
+	}
+	if len(r.Errors) != 0 || len(r.Warnings) != 0 {
+		t.Errorf("errors/warnings = %q/%q, want none from the empty elements", r.Errors, r.Warnings)
+	}
+	if r.Data.NumResults != 2 || len(r.Data.Tafs) != 2 {
+		t.Fatalf("got %d TAFs (num_results %d), want 2", len(r.Data.Tafs), r.Data.NumResults)
+	}
+
+	blv := r.Data.Tafs[0]
+	if blv.StationId != "KBLV" || !blv.ValidTimeTo.Equal(time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)) || blv.ElevationM != 137 {
+		t.Errorf("KBLV station/valid to/elevation = %s/%v/%v", blv.StationId, blv.ValidTimeTo, blv.ElevationM)
+	}
+	if len(blv.Forecast) != 2 {
+		t.Fatalf("KBLV has %d forecasts, want 2", len(blv.Forecast))
+	}
+
+	base, becmg := blv.Forecast[0], blv.Forecast[1]
+	if base.AltimInHg != 29.920275 {
+		t.Errorf("altim_in_hg = %v, want 29.920275", base.AltimInHg)
+	}
+	if want := []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 3000}}; !reflect.DeepEqual(stripSkyNames(base.SkyCondition), want) {
+		t.Errorf("sky_condition = %+v, want %+v", base.SkyCondition, want)
+	}
+	if len(base.TurbulenceCondition) != 1 || len(base.IcingCondition) != 1 {
+		t.Fatalf("turbulence_condition/icing_condition = %+v/%+v, want one of each", base.TurbulenceCondition, base.IcingCondition)
+	}
+	if turb := base.TurbulenceCondition[0]; turb.Intensity != "2" || turb.MinAltFtAGL != 1000 || turb.MaxAltFtAGL != 7000 {
+		t.Errorf("turbulence_condition = %+v", turb)
+	}
+	if ice := base.IcingCondition[0]; ice.Intensity != "2" || ice.MinAltFtAGL != 3000 || ice.MaxAltFtAGL != 7000 {
+		t.Errorf("icing_condition = %+v", ice)
+	}
+	if len(base.Temperature) != 2 {
+		t.Fatalf("temperature = %+v, want a maximum and a minimum", base.Temperature)
+	}
+	if max := base.Temperature[0]; max.MaxTempC == nil || *max.MaxTempC != 24 || max.MinTempC != nil || max.SfcTempC != nil ||
+		!max.ValidTime.Equal(time.Date(2026, 10, 18, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("maximum temperature = %+v", max)
+	}
+	if min := base.Temperature[1]; min.MinTempC == nil || *min.MinTempC != 8 || min.MaxTempC != nil {
+		t.Errorf("minimum temperature = %+v", min)
+	}
+
+	if becmg.ChangeIndicator != "BECMG" || !becmg.TimeBecoming.Equal(time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("BECMG change/becoming = %s/%v", becmg.ChangeIndicator, becmg.TimeBecoming)
+	}
+	if becmg.VertVisFt != 200 || becmg.AltimInHg != 29.899607 || becmg.WxString != "FG" {
+		t.Errorf("vert_vis_ft/altim_in_hg/wx_string = %d/%v/%s", becmg.VertVisFt, becmg.AltimInHg, becmg.WxString)
+	}
+	if want := []SkyCondition{{SkyCover: "OVX"}}; !reflect.DeepEqual(stripSkyNames(becmg.SkyCondition), want) {
+		t.Errorf("sky_condition = %+v, want %+v", becmg.SkyCondition, want)
+	}
+	if len(becmg.Temperature) != 0 || len(becmg.TurbulenceCondition) != 0 {
+		t.Errorf("BECMG temperature/turbulence = %+v/%+v, want none", becmg.Temperature, becmg.TurbulenceCondition)
+	}
+
+	from := r.Data.Tafs[1].Forecast[1]
+	if want := []SkyCondition{{SkyCover: "BKN", CloudBaseFtAGL: 6000, CloudType: "CB"}}; !reflect.DeepEqual(stripSkyNames(from.SkyCondition), want) {
+		t.Errorf("KDEN FM sky_condition = %+v, want %+v", from.SkyCondition, want)
+	}
+	if from.WindGustKt != 25 || from.AltimInHg != 0 || from.VertVisFt != 0 {
+		t.Errorf("KDEN FM gust/altimeter/vertical visibility = %d/%v/%d", from.WindGustKt, from.AltimInHg, from.VertVisFt)
+	}
+}
+
+// stripSkyNames clears the XMLName the decoder fills in so sky conditions can be compared with literals
+func stripSkyNames(sky []SkyCondition) []SkyCondition {
+	for i := range sky {
+		sky[i].XMLName = xml.Name{}
+	}
+	return sky
+}
+
+func TestCsvRecordsWithoutForecasts(t *testing.T) {
+	r := Response{}
+	r.Data.Tafs = []Taf{
+		{StationId: "KSEA", ValidTimeFrom: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), Latitude: 47.45},
+		{StationId: "KDEN", Forecast: []Forecast{{WindSpeedKt: 5}, {ChangeIndicator: "FM", WindSpeedKt: 15}}},
+	}
+
+	s, err := r.ToCsv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d rows, want the header, one row for the NIL TAF and two forecast rows", len(records))
+	}
+
+	header := CsvHeader()
+	nilTaf := records[1]
+	for col, value := range nilTaf {
+		switch header[col] {
+		case "station_id":
+			if value != "KSEA" {
+				t.Errorf("station_id = %q, want KSEA", value)
+			}
+		case "valid_time_from":
+			if value != "2026-10-18T12:00:00Z" {
+				t.Errorf("valid_time_from = %q", value)
+			}
+		case "latitude":
+			if value != "47.45" {
+				t.Errorf("latitude = %q", value)
+			}
+		case "issue_time", "valid_time_to", "longitude", "elevation_m":
+		default:
+			if value != "" {
+				t.Errorf("forecast column %s = %q, want empty", header[col], value)
+			}
+		}
+	}
+	if records[3][9] != "FM" {
+		t.Errorf("change_indicator of the last row = %q, want FM", records[3][9])
+	}
+}
@@ -0,0 +1,186 @@
+// Package wx decodes present weather groups like -SHRA, +TSRAGR or VCFG as used in METARs and TAFs
+package wx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Phenomenon is one decoded present weather group
+type Phenomenon struct {
+	Raw           string
+	Intensity     string   `json:",omitempty"` // - (light), + (heavy) or empty
+	Proximity     string   `json:",omitempty"` // VC (in the vicinity) or empty
+	Descriptors   []string `json:",omitempty"` // up to two of MI, PR, BC, DR, BL, SH, TS or FZ, e.g. TS and SH for TSSHRA
+	Precipitation []string `json:",omitempty"` // DZ, RA, SN, SG, IC, PL, GR, GS or UP
+	Obscuration   []string `json:",omitempty"` // BR, FG, FU, VA, DU, SA, HZ or PY
+	Other         []string `json:",omitempty"` // PO, SQ, FC, SS, DS or NSW
+}
+
+var descriptors = map[string]string{
+	"MI": "shallow",
+	"PR": "partial",
+	"BC": "patches of",
+	"DR": "low drifting",
+	"BL": "blowing",
+	"SH": "showers",
+	"TS": "thunderstorm",
+	"FZ": "freezing",
+}
+
+var precipitation = map[string]string{
+	"DZ": "drizzle",
+	"RA": "rain",
+	"SN": "snow",
+	"SG": "snow grains",
+	"IC": "ice crystals",
+	"PL": "ice pellets",
+	"GR": "hail",
+	"GS": "small hail",
+	"UP": "unknown precipitation",
+}
+
+var obscuration = map[string]string{
+	"BR": "mist",
+	"FG": "fog",
+	"FU": "smoke",
+	"VA": "volcanic ash",
+	"DU": "widespread dust",
+	"SA": "sand",
+	"HZ": "haze",
+	"PY": "spray",
+}
+
+var other = map[string]string{
+	"PO":  "dust/sand whirls",
+	"SQ":  "squalls",
+	"FC":  "funnel cloud",
+	"SS":  "sandstorm",
+	"DS":  "duststorm",
+	"NSW": "no significant weather",
+}
+
+// Parse decodes a space separated list of present weather groups such as the wx_string of a METAR
+func Parse(s string) ([]Phenomenon, error) {
+	var phenomena []Phenomenon
+	for _, g := range strings.Fields(s) {
+		p, err := ParseGroup(g)
+		if err != nil {
+			return nil, err
+		}
+		phenomena = append(phenomena, p)
+	}
+	return phenomena, nil
+}
+
+// ParseGroup decodes a single present weather group
+func ParseGroup(g string) (Phenomenon, error) {
+	p := Phenomenon{Raw: g}
+	if g == "NSW" {
+		p.Other = []string{g}
+		return p, nil
+	}
+
+	rest := g
+	switch {
+	case strings.HasPrefix(rest, "-") || strings.HasPrefix(rest, "+"):
+		p.Intensity, rest = rest[:1], rest[1:]
+	case strings.HasPrefix(rest, "VC"):
+		p.Proximity, rest = "VC", rest[2:]
+	}
+	for len(rest) >= 2 && len(p.Descriptors) < 2 {
+		if _, ok := descriptors[rest[:2]]; !ok {
+			break
+		}
+		p.Descriptors, rest = append(p.Descriptors, rest[:2]), rest[2:]
+	}
+
+	for len(rest) > 0 {
+		if len(rest) < 2 {
+			return Phenomenon{}, fmt.Errorf("invalid weather group %q", g)
+		}
+		code := rest[:2]
+		if _, ok := precipitation[code]; ok {
+			p.Precipitation = append(p.Precipitation, code)
+		} else if _, ok := obscuration[code]; ok {
+			p.Obscuration = append(p.Obscuration, code)
+		} else if _, ok := other[code]; ok && code != "NSW" {
+			p.Other = append(p.Other, code)
+		} else {
+			return Phenomenon{}, fmt.Errorf("unknown weather %q in group %q", code, g)
+		}
+		rest = rest[2:]
+	}
+
+	// only a thunderstorm and showers in the vicinity are weather without a phenomenon, like TS, VCSH or VCTSSH
+	alone := len(p.Descriptors) > 0
+	for _, d := range p.Descriptors {
+		alone = alone && (d == "TS" || d == "SH" && p.Proximity == "VC")
+	}
+	if !alone && len(p.Precipitation)+len(p.Obscuration)+len(p.Other) == 0 {
+		return Phenomenon{}, fmt.Errorf("invalid weather group %q", g)
+	}
+	return p, nil
+}
+
+// Description returns a human-readable description like "light rain showers" or "thunderstorm in the vicinity"
+func (p Phenomenon) Description() string {
+	var kinds []string
+	for _, c := range p.Precipitation {
+		kinds = append(kinds, precipitation[c])
+	}
+	for _, c := range p.Obscuration {
+		kinds = append(kinds, obscuration[c])
+	}
+	for _, c := range p.Other {
+		if c == "FC" && p.Intensity == "+" {
+			kinds = append(kinds, "tornado or waterspout")
+			continue
+		}
+		kinds = append(kinds, other[c])
+	}
+	what := strings.Join(kinds, " and ")
+
+	var words []string
+	switch p.Intensity {
+	case "-":
+		words = append(words, "light")
+	case "+":
+		if !(len(p.Other) == 1 && p.Other[0] == "FC") {
+			words = append(words, "heavy")
+		}
+	}
+
+	// descriptors other than TS qualify the weather, a thunderstorm comes with it
+	thunderstorm := false
+	for _, d := range p.Descriptors {
+		switch d {
+		case "TS":
+			thunderstorm = true
+		case "SH":
+			what += " showers"
+		default:
+			what = descriptors[d] + " " + what
+		}
+	}
+	what = strings.TrimSpace(what)
+
+	switch {
+	case !thunderstorm:
+		words = append(words, what)
+	case len(what) > 0:
+		words = append(words, "thunderstorm with", what)
+	default:
+		words = append(words, "thunderstorm")
+	}
+
+	if p.Proximity == "VC" {
+		words = append(words, "in the vicinity")
+	}
+
+	return strings.Join(strings.Fields(strings.Join(words, " ")), " ")
+}
+
+func (p Phenomenon) String() string {
+	return p.Raw
+}
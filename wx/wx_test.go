@@ -0,0 +1,80 @@
+package wx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		group       string
+		want        Phenomenon
+		description string
+	}{
+		{"-RA", Phenomenon{Raw: "-RA", Intensity: "-", Precipitation: []string{"RA"}}, "light rain"},
+		{"+SHRA", Phenomenon{Raw: "+SHRA", Intensity: "+", Descriptors: []string{"SH"}, Precipitation: []string{"RA"}}, "heavy rain showers"},
+		{"-SHRASN", Phenomenon{Raw: "-SHRASN", Intensity: "-", Descriptors: []string{"SH"}, Precipitation: []string{"RA", "SN"}}, "light rain and snow showers"},
+		{"TS", Phenomenon{Raw: "TS", Descriptors: []string{"TS"}}, "thunderstorm"},
+		{"+TSRAGR", Phenomenon{Raw: "+TSRAGR", Intensity: "+", Descriptors: []string{"TS"}, Precipitation: []string{"RA", "GR"}}, "heavy thunderstorm with rain and hail"},
+		{"TSSHRA", Phenomenon{Raw: "TSSHRA", Descriptors: []string{"TS", "SH"}, Precipitation: []string{"RA"}}, "thunderstorm with rain showers"},
+		{"-TSSHRAGS", Phenomenon{Raw: "-TSSHRAGS", Intensity: "-", Descriptors: []string{"TS", "SH"}, Precipitation: []string{"RA", "GS"}}, "light thunderstorm with rain and small hail showers"},
+		{"VCSH", Phenomenon{Raw: "VCSH", Proximity: "VC", Descriptors: []string{"SH"}}, "showers in the vicinity"},
+		{"VCTSSH", Phenomenon{Raw: "VCTSSH", Proximity: "VC", Descriptors: []string{"TS", "SH"}}, "thunderstorm with showers in the vicinity"},
+		{"VCFG", Phenomenon{Raw: "VCFG", Proximity: "VC", Obscuration: []string{"FG"}}, "fog in the vicinity"},
+		{"FZFG", Phenomenon{Raw: "FZFG", Descriptors: []string{"FZ"}, Obscuration: []string{"FG"}}, "freezing fog"},
+		{"-FZDZ", Phenomenon{Raw: "-FZDZ", Intensity: "-", Descriptors: []string{"FZ"}, Precipitation: []string{"DZ"}}, "light freezing drizzle"},
+		{"BLSN", Phenomenon{Raw: "BLSN", Descriptors: []string{"BL"}, Precipitation: []string{"SN"}}, "blowing snow"},
+		{"MIFG", Phenomenon{Raw: "MIFG", Descriptors: []string{"MI"}, Obscuration: []string{"FG"}}, "shallow fog"},
+		{"BR", Phenomenon{Raw: "BR", Obscuration: []string{"BR"}}, "mist"},
+		{"+FC", Phenomenon{Raw: "+FC", Intensity: "+", Other: []string{"FC"}}, "tornado or waterspout"},
+		{"SQ", Phenomenon{Raw: "SQ", Other: []string{"SQ"}}, "squalls"},
+		{"NSW", Phenomenon{Raw: "NSW", Other: []string{"NSW"}}, "no significant weather"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			p, err := ParseGroup(tt.group)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("ParseGroup(%q) = %+v, want %+v", tt.group, p, tt.want)
+			}
+			if got := p.Description(); got != tt.description {
+				t.Errorf("Description() = %q, want %q", got, tt.description)
+			}
+			if p.String() != tt.group {
+				t.Errorf("String() = %q, want %q", p.String(), tt.group)
+			}
+		})
+	}
+}
+
+func TestParseGroupErrors(t *testing.T) {
+	for _, g := range []string{"", "-", "VC", "XX", "RAX", "TSSHFZRA", "KORD", "10SM", "-FZ", "FZ", "BL", "SH", "+SH", "VCBL", "TSFZ"} {
+		if p, err := ParseGroup(g); err == nil {
+			t.Errorf("ParseGroup(%q) = %+v, want an error", g, p)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	phenomena, err := Parse("-TSSHRA BR VCFG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range phenomena {
+		got = append(got, p.Description())
+	}
+	if want := []string{"light thunderstorm with rain showers", "mist", "fog in the vicinity"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() descriptions = %q, want %q", got, want)
+	}
+
+	if _, err := Parse("-RA XYZ"); err == nil {
+		t.Error("Parse() accepted an invalid group")
+	}
+	if phenomena, err := Parse(""); err != nil || len(phenomena) != 0 {
+		t.Errorf(`Parse("") = %v, %v`, phenomena, err)
+	}
+}